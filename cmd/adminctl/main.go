@@ -0,0 +1,265 @@
+// Command adminctl menjalankan tugas operasional (support dan rotasi kunci) langsung terhadap
+// database dan Redis yang sama dengan server. Setiap perubahan dicatat di audit log dengan
+// aktor "adminctl:<user OS>". Password dibaca dari baris pertama stdin agar tidak muncul di
+// riwayat shell.
+//
+// Penggunaan:
+//
+//	adminctl create-user -email EMAIL [-role ROLE] [-locale LOCALE] [-verified] < password.txt
+//	adminctl verify USER
+//	adminctl reset-password [-send] USER
+//	adminctl revoke-sessions USER
+//	adminctl list-users [-q QUERY] [-page N] [-limit N]
+//	adminctl rotate-keys [-keep N]
+//
+// USER dapat berupa ID atau email.
+package main
+
+import (
+	"auth-service/config"
+	"auth-service/mailer"
+	"auth-service/model"
+	"auth-service/repository"
+	"auth-service/service"
+	"auth-service/utils"
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/user"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("FATAL: Tidak dapat memuat konfigurasi: %v", err)
+	}
+
+	// rotate-keys hanya membaca konfigurasi sehingga tidak perlu menyiapkan service.
+	if os.Args[1] == "rotate-keys" {
+		runRotateKeys(cfg, os.Args[2:])
+		return
+	}
+
+	userRepo := repository.NewUserRepo(cfg.DB)
+	redisRepo := repository.NewRedisRepo(cfg.Redis)
+	auditKey := []byte(cfg.AuditHMACKey)
+	auditService := service.NewAuditService(repository.NewAuditRepo(cfg.DB, auditKey), auditKey)
+	webhookService := service.NewWebhookService(repository.NewWebhookRepo(cfg.DB), cfg)
+	outboxService := service.NewOutboxService(repository.NewOutboxRepo(cfg.DB), webhookService, cfg)
+	mail, err := mailer.New(cfg)
+	if err != nil {
+		log.Fatalf("FATAL: Tidak dapat menyiapkan mailer: %v", err)
+	}
+	// Dengan antrean aktif, email hanya dimasukkan ke Redis dan dikirim oleh worker di server.
+	if cfg.MailQueueEnabled {
+		mail = mailer.NewQueueMailer(repository.NewMailQueueRepo(cfg.Redis), mail, cfg.MailWorkers, cfg.MailMaxAttempts)
+	}
+	emailTemplates, err := mailer.NewRenderer(cfg.MailTemplateDir, cfg.AppName, cfg.MailDefaultLocale)
+	if err != nil {
+		log.Fatalf("FATAL: Tidak dapat memuat template email: %v", err)
+	}
+	adminService := service.NewAdminService(userRepo, redisRepo, auditService, outboxService, mail, emailTemplates, cfg)
+
+	cli := &adminCLI{
+		ctx:      operatorContext(),
+		admin:    adminService,
+		userRepo: userRepo,
+		validate: validator.New(),
+	}
+
+	switch os.Args[1] {
+	case "create-user":
+		cli.createUser(os.Args[2:])
+	case "verify":
+		cli.verify(os.Args[2:])
+	case "reset-password":
+		cli.resetPassword(os.Args[2:])
+	case "revoke-sessions":
+		cli.revokeSessions(os.Args[2:])
+	case "list-users":
+		cli.listUsers(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: adminctl create-user -email EMAIL [-role ROLE] [-locale LOCALE] [-verified] < password")
+	fmt.Fprintln(os.Stderr, "       adminctl verify USER")
+	fmt.Fprintln(os.Stderr, "       adminctl reset-password [-send] USER")
+	fmt.Fprintln(os.Stderr, "       adminctl revoke-sessions USER")
+	fmt.Fprintln(os.Stderr, "       adminctl list-users [-q QUERY] [-page N] [-limit N]")
+	fmt.Fprintln(os.Stderr, "       adminctl rotate-keys [-keep N]")
+	os.Exit(2)
+}
+
+// operatorContext mengisi aktor audit log dengan nama user OS yang menjalankan perintah dan
+// menandai operasi sebagai operasi operator, yang tidak dibatasi role.
+func operatorContext() context.Context {
+	operator := "unknown"
+	if u, err := user.Current(); err == nil {
+		operator = u.Username
+	}
+	ctx := context.WithValue(model.WithOperator(context.Background()), model.ContextKey("userID"), "adminctl:"+operator)
+	return model.WithClientInfo(ctx, model.ClientInfo{UserAgent: "adminctl"})
+}
+
+type adminCLI struct {
+	ctx      context.Context
+	admin    *service.AdminService
+	userRepo repository.UserStore
+	validate *validator.Validate
+}
+
+func (c *adminCLI) createUser(args []string) {
+	fs := flag.NewFlagSet("create-user", flag.ExitOnError)
+	email := fs.String("email", "", "email address")
+	role := fs.String("role", model.RoleUser, "role: user, admin or superadmin")
+	locale := fs.String("locale", "", "email locale; must have templates in MAIL_TEMPLATE_DIR")
+	verified := fs.Bool("verified", false, "mark the email as verified")
+	fs.Parse(args)
+
+	input := model.CreateUserInput{
+		Email:    strings.TrimSpace(*email),
+		Password: readPassword(),
+		Role:     *role,
+		Locale:   *locale,
+		Verified: *verified,
+	}
+	if err := c.validate.Struct(input); err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+
+	created, err := c.admin.CreateUser(c.ctx, input)
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+	fmt.Printf("created user %s (%s, role %s, verified %t)\n", created.ID, created.Email, created.Role, created.IsVerified)
+}
+
+func (c *adminCLI) verify(args []string) {
+	id := c.userArg("verify", args)
+	verified, err := c.admin.VerifyUser(c.ctx, id)
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+	fmt.Printf("user %s (%s) is verified\n", verified.ID, verified.Email)
+}
+
+func (c *adminCLI) resetPassword(args []string) {
+	fs := flag.NewFlagSet("reset-password", flag.ExitOnError)
+	send := fs.Bool("send", false, "email a reset link instead of reading a new password from stdin")
+	fs.Parse(args)
+	id := c.userArg("reset-password", fs.Args())
+
+	if *send {
+		if err := c.admin.ForcePasswordReset(c.ctx, id); err != nil {
+			log.Fatalf("FATAL: %v", err)
+		}
+		fmt.Printf("sessions revoked and reset email sent to user %s\n", id)
+		return
+	}
+
+	input := model.SetPasswordInput{Password: readPassword()}
+	if err := c.validate.Struct(input); err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+	if err := c.admin.SetPassword(c.ctx, id, input); err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+	fmt.Printf("password updated and sessions revoked for user %s\n", id)
+}
+
+func (c *adminCLI) revokeSessions(args []string) {
+	id := c.userArg("revoke-sessions", args)
+	revoked, err := c.admin.RevokeSessions(c.ctx, id)
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+	fmt.Printf("revoked %d sessions for user %s\n", revoked, id)
+}
+
+func (c *adminCLI) listUsers(args []string) {
+	fs := flag.NewFlagSet("list-users", flag.ExitOnError)
+	query := fs.String("q", "", "search by email")
+	page := fs.Int("page", 1, "page number")
+	limit := fs.Int("limit", 50, "maximum number of users")
+	fs.Parse(args)
+
+	result, err := c.admin.ListUsers(c.ctx, model.ListUsersInput{Query: *query, Page: *page, PageSize: *limit})
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tEMAIL\tROLE\tSTATUS\tVERIFIED\tCREATED")
+	for _, u := range result.Users {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%t\t%s\n",
+			u.ID, u.Email, u.Role, u.Status, u.IsVerified, u.CreatedAt.Format(time.RFC3339))
+	}
+	tw.Flush()
+	fmt.Printf("\npage %d, showing %d of %d users\n", result.Page, len(result.Users), result.Total)
+}
+
+// runRotateKeys membuat JWT secret baru dan mencetak nilai environment yang harus dipasang.
+// Secret aktif dipindahkan ke JWT_PREVIOUS_SECRETS agar token yang sudah terbit tetap
+// berlaku sampai kedaluwarsa; keep membatasi jumlah secret lama yang dipertahankan.
+func runRotateKeys(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("rotate-keys", flag.ExitOnError)
+	keep := fs.Int("keep", 1, "number of previous secrets to keep accepting")
+	fs.Parse(args)
+	if *keep < 1 {
+		log.Fatalf("FATAL: -keep must be at least 1")
+	}
+
+	previous := append([]string{cfg.JwtSecret}, cfg.JwtPreviousSecrets...)
+	if len(previous) > *keep {
+		previous = previous[:*keep]
+	}
+
+	fmt.Printf("JWT_SECRET=%s\n", utils.GenerateSecureRandomString(32))
+	fmt.Printf("JWT_PREVIOUS_SECRETS=%s\n", strings.Join(previous, ","))
+	fmt.Fprintln(os.Stderr, "apply these values to every instance; remove the previous secrets once")
+	fmt.Fprintf(os.Stderr, "access tokens (%s) and verification links (%s) signed with them have expired\n",
+		cfg.AccessTokenDuration, cfg.EmailVerificationDuration)
+}
+
+// userArg membaca argumen USER dan mengubah email menjadi ID.
+func (c *adminCLI) userArg(command string, args []string) string {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "usage: adminctl %s USER\n", command)
+		os.Exit(2)
+	}
+	if _, err := uuid.Parse(args[0]); err == nil {
+		return args[0]
+	}
+
+	found, err := c.userRepo.FindByEmail(c.ctx, args[0])
+	if err != nil {
+		log.Fatalf("FATAL: %v", model.ErrUserNotFound)
+	}
+	return found.ID.String()
+}
+
+func readPassword() string {
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		log.Fatalf("FATAL: could not read password from stdin: %v", err)
+	}
+	return strings.TrimRight(line, "\r\n")
+}
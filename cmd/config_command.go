@@ -0,0 +1,49 @@
+package main
+
+import (
+	"auth-service/config"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// runConfigCommand menjalankan "config validate" dan "config print [--redacted]" dengan flag
+// konfigurasi yang sama seperti server, tanpa membuka koneksi ke database atau Redis.
+func runConfigCommand(args []string) {
+	if len(args) < 1 {
+		configUsage()
+	}
+	// Log hanya ditulis ke stderr agar keluaran "config print" tetap berupa YAML yang valid.
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})))
+
+	switch args[0] {
+	case "validate":
+		fs := flag.NewFlagSet("config validate", flag.ExitOnError)
+		if _, err := config.Load(fs, args[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "configuration is invalid: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("configuration is valid")
+	case "print":
+		fs := flag.NewFlagSet("config print", flag.ExitOnError)
+		redacted := fs.Bool("redacted", false, "replace secret values with [REDACTED]")
+		cfg, err := config.Load(fs, args[1:])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "configuration is invalid: %v\n", err)
+			os.Exit(1)
+		}
+		if err := cfg.WriteYAML(os.Stdout, *redacted); err != nil {
+			fmt.Fprintf(os.Stderr, "could not print configuration: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		configUsage()
+	}
+}
+
+func configUsage() {
+	fmt.Fprintln(os.Stderr, "usage: auth-service config validate [-config FILE] [flags]")
+	fmt.Fprintln(os.Stderr, "       auth-service config print [-redacted] [-config FILE] [flags]")
+	os.Exit(2)
+}
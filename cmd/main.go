@@ -3,11 +3,27 @@ package main
 import (
 	"auth-service/config"
 	"auth-service/controller"
+	"auth-service/logger"
+	"auth-service/mailer"
+	"auth-service/metrics"
+	appmiddleware "auth-service/middleware"
+	"auth-service/migrations"
+	"auth-service/otp"
 	"auth-service/repository"
 	"auth-service/routes"
 	"auth-service/service"
-	"log"
+	"auth-service/tracing"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -15,45 +31,248 @@ import (
 )
 
 func main() {
-	// Log-log ini akan membantu Anda melacak titik kegagalan.
-	// Perhatikan output di terminal Anda saat menjalankan program.
-	log.Println("--- [Step 1] Memuat konfigurasi ---")
-	cfg, err := config.LoadConfig()
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		runConfigCommand(os.Args[2:])
+		return
+	}
+
+	// Logger awal dipakai sampai konfigurasi (LOG_LEVEL, LOG_FORMAT) selesai dimuat.
+	bootLogger, _ := logger.New(os.Stdout, "info", "json")
+	slog.SetDefault(bootLogger)
+
+	slog.Info("loading configuration", "step", 1)
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		fatal("could not load configuration", err)
+	}
+	if err := cfg.Connect(context.Background()); err != nil {
+		fatal("could not connect to dependencies", err)
+	}
+	appLogger, err := logger.New(os.Stdout, cfg.LogLevel, cfg.LogFormat)
 	if err != nil {
-		log.Fatalf("FATAL: Tidak dapat memuat konfigurasi: %v", err)
+		fatal("could not initialize logger", err)
 	}
-	log.Println("--- [Step 1] Konfigurasi berhasil dimuat ---")
+	slog.SetDefault(appLogger)
+	slog.Info("configuration loaded", "step", 1, "log_level", cfg.LogLevel, "storage", cfg.StorageDriver)
 
-	log.Println("--- [Step 2] Menginisialisasi validator ---")
+	// Mode memori tidak memakai Postgres dan Redis: migrasi, instrumentasi koneksi dan antrean
+	// email (yang disimpan di Redis) dilewati.
+	inMemory := cfg.StorageDriver == config.StorageMemory
+	if inMemory {
+		slog.Warn("using in-memory storage; all data is lost when the server stops")
+	}
+
+	if cfg.MigrateOnStart && !inMemory {
+		slog.Info("running database migrations")
+		migrator, err := migrations.New(cfg.DB)
+		if err != nil {
+			fatal("could not load migrations", err)
+		}
+		applied, err := migrator.Up(context.Background())
+		if err != nil {
+			fatal("could not run migrations", err)
+		}
+		slog.Info("database migrations complete", "applied", len(applied))
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg)
+	if err != nil {
+		fatal("could not initialize tracing", err)
+	}
+	if !inMemory {
+		if err := tracing.InstrumentGORM(cfg.DB); err != nil {
+			fatal("could not instrument database for tracing", err)
+		}
+		cfg.Redis.AddHook(tracing.RedisHook{})
+	}
+
+	slog.Info("initializing validator", "step", 2)
 	validate := validator.New()
-	log.Println("--- [Step 2] Validator berhasil diinisialisasi ---")
+	slog.Info("validator initialized", "step", 2)
 
-	log.Println("--- [Step 3] Menginisialisasi repositories ---")
-	userRepo := repository.NewUserRepo(cfg.DB)
-	redisRepo := repository.NewRedisRepo(cfg.Redis)
-	log.Println("--- [Step 3] Repositories berhasil diinisialisasi ---")
+	slog.Info("initializing repositories", "step", 3)
+	var stores *repository.Stores
+	if inMemory {
+		stores = repository.NewMemoryStores([]byte(cfg.AuditHMACKey))
+	} else {
+		stores = repository.NewStores(cfg.DB, cfg.Redis, []byte(cfg.AuditHMACKey))
+	}
+	slog.Info("repositories initialized", "step", 3)
 
-	log.Println("--- [Step 4] Menginisialisasi services ---")
-	authService := service.NewAuthService(userRepo, redisRepo, cfg)
-	log.Println("--- [Step 4] Services berhasil diinisialisasi ---")
+	slog.Info("initializing services", "step", 4)
+	auditService := service.NewAuditService(stores.Audit, []byte(cfg.AuditHMACKey))
+	webhookService := service.NewWebhookService(stores.Webhooks, cfg)
+	outboxService := service.NewOutboxService(stores.Outbox, webhookService, cfg)
+	mail, err := mailer.New(cfg)
+	if err != nil {
+		fatal("could not initialize mailer", err)
+	}
+	emailTemplates, err := mailer.NewRenderer(cfg.MailTemplateDir, cfg.AppName, cfg.MailDefaultLocale)
+	if err != nil {
+		fatal("could not load email templates", err)
+	}
+	mailBackend := mail
+	var mailQueue *mailer.QueueMailer
+	if cfg.MailQueueEnabled && !inMemory {
+		mailQueue = mailer.NewQueueMailer(repository.NewMailQueueRepo(cfg.Redis), mail, cfg.MailWorkers, cfg.MailMaxAttempts)
+		mail = mailQueue
+	}
+	otpDispatcher, err := otp.New(cfg, mail, emailTemplates)
+	if err != nil {
+		fatal("could not initialize OTP channels", err)
+	}
+	authService := service.NewAuthService(stores.Users, stores.Tokens, auditService, outboxService, mail, emailTemplates, otpDispatcher, cfg)
+	healthService := service.NewHealthService(cfg.DB, cfg.Redis, mailBackend, cfg)
+	adminService := service.NewAdminService(stores.Users, stores.Tokens, auditService, outboxService, mail, emailTemplates, cfg)
+	slog.Info("services initialized", "step", 4)
 
-	log.Println("--- [Step 5] Menginisialisasi controllers ---")
-	authController := controller.NewAuthController(authService, validate)
-	log.Println("--- [Step 5] Controllers berhasil diinisialisasi ---")
+	slog.Info("initializing controllers", "step", 5)
+	authController := controller.NewAuthController(authService, validate, cfg.EmailVerificationRedirect)
+	adminController := controller.NewAdminController(adminService, webhookService, validate)
+	auditController := controller.NewAuditController(auditService)
+	securityController := controller.NewSecurityController(auditService)
+	healthController := controller.NewHealthController(healthService)
+	slog.Info("controllers initialized", "step", 5)
 
-	log.Println("--- [Step 6] Menyiapkan router dan middleware ---")
+	slog.Info("setting up router and middleware", "step", 6)
 	r := chi.NewRouter()
-	r.Use(middleware.Logger)
+	r.Use(middleware.RequestID)
+	r.Use(appmiddleware.Tracing)
+	r.Use(appmiddleware.RequestLogger)
+	r.Use(appmiddleware.Metrics)
 	r.Use(middleware.Recoverer)
-	log.Println("--- [Step 6] Router dan middleware berhasil disiapkan ---")
+	r.Use(appmiddleware.ClientInfo)
+	slog.Info("router and middleware ready", "step", 6)
+
+	slog.Info("setting up routes", "step", 7)
+	routes.SetupRoutes(r, authController, adminController, auditController, securityController, healthController, stores.Users, cfg)
+	slog.Info("routes ready", "step", 7)
+
+	// Setting bertanda reload (durasi token, mode verifikasi, log level, template email)
+	// dimuat ulang saat SIGHUP atau saat file konfigurasi berubah.
+	reloader := config.NewReloader(cfg, os.Args[1:])
+	reloader.OnReload(func(next *config.Config) error {
+		if err := emailTemplates.Reload(next.MailTemplateDir, next.AppName, next.MailDefaultLocale); err != nil {
+			return fmt.Errorf("could not reload email templates: %w", err)
+		}
+		return logger.SetLevel(next.LogLevel)
+	})
+
+	slog.Info("starting outbox dispatcher, mail workers and config reloader", "step", 8)
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		outboxService.Run(workerCtx)
+	}()
+	if mailQueue != nil {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			mailQueue.Run(workerCtx)
+		}()
+	}
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		reloader.Run(workerCtx)
+	}()
+
+	slog.Info("starting server", "step", 9, "port", cfg.Port)
+	server := &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           r,
+		ReadHeaderTimeout: cfg.HTTPReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTPReadTimeout,
+		WriteTimeout:      cfg.HTTPWriteTimeout,
+		IdleTimeout:       cfg.HTTPIdleTimeout,
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+	}
+	signalCtx, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+	serverErr := make(chan error, 2)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+
+	// Metrics dilayani di listener terpisah (METRICS_ADDR, default hanya localhost) agar tidak
+	// terbuka di port publik bersama API. METRICS_ADDR kosong mematikan endpoint ini.
+	var metricsServer *http.Server
+	if cfg.MetricsAddr != "" {
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle("/metrics", metrics.Handler())
+		metricsServer = &http.Server{
+			Addr:              cfg.MetricsAddr,
+			Handler:           metricsMux,
+			ReadHeaderTimeout: cfg.HTTPReadHeaderTimeout,
+			ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+		}
+		slog.Info("starting metrics server", "addr", cfg.MetricsAddr)
+		go func() {
+			serverErr <- metricsServer.ListenAndServe()
+		}()
+	}
+
+	select {
+	case err := <-serverErr:
+		fatal("server stopped", err)
+	case <-signalCtx.Done():
+	}
+
+	// Urutan shutdown: tolak readiness dan tunggu SHUTDOWN_DRAIN_DELAY agar probe load balancer
+	// sempat melihat 503 sementara request baru masih dilayani, selesaikan request yang sedang
+	// berjalan, hentikan worker (job yang sedang diproses dituntaskan), lalu tutup koneksi ke
+	// dependency.
+	slog.Info("shutting down", "drain_delay", cfg.ShutdownDrainDelay, "timeout", cfg.ShutdownTimeout)
+	healthService.MarkShuttingDown()
+	time.Sleep(cfg.ShutdownDrainDelay)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("could not drain HTTP requests", "error", err)
+	}
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+			slog.Error("could not stop metrics server", "error", err)
+		}
+	}
 
-	log.Println("--- [Step 7] Menyiapkan rute ---")
-	routes.SetupRoutes(r, authController, cfg)
-	log.Println("--- [Step 7] Rute berhasil disiapkan ---")
+	stopWorkers()
+	drained := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-shutdownCtx.Done():
+		slog.Error("background workers did not stop before the shutdown timeout")
+	}
 
-	log.Println("--- [Step 8] Memulai server ---")
-	log.Printf("🚀 Server dimulai pada port :%s", cfg.Port)
-	if err := http.ListenAndServe(":"+cfg.Port, r); err != nil {
-		log.Fatalf("FATAL: Gagal memulai server: %v", err)
+	if closer, ok := mailBackend.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			slog.Error("could not close mailer", "error", err)
+		}
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("could not flush traces", "error", err)
+	}
+	if !inMemory {
+		if err := cfg.Redis.Close(); err != nil {
+			slog.Error("could not close redis client", "error", err)
+		}
+		if sqlDB, err := cfg.DB.DB(); err == nil {
+			if err := sqlDB.Close(); err != nil {
+				slog.Error("could not close database", "error", err)
+			}
+		}
 	}
+	slog.Info("shutdown complete")
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
@@ -0,0 +1,135 @@
+// Command migrate menjalankan migrasi skema database yang disematkan di paket migrations.
+//
+// Penggunaan:
+//
+//	migrate up
+//	migrate down [-steps N]
+//	migrate status
+//	migrate baseline VERSION
+//
+// baseline menandai migrasi hingga VERSION sebagai sudah dijalankan tanpa menjalankannya,
+// untuk database yang skemanya dibuat manual sebelum ada migrasi.
+package main
+
+import (
+	"auth-service/config"
+	"auth-service/migrations"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("FATAL: Tidak dapat memuat konfigurasi: %v", err)
+	}
+	migrator, err := migrations.New(cfg.DB)
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+	ctx := context.Background()
+
+	switch os.Args[1] {
+	case "up":
+		runUp(ctx, migrator)
+	case "down":
+		runDown(ctx, migrator, os.Args[2:])
+	case "status":
+		runStatus(ctx, migrator)
+	case "baseline":
+		runBaseline(ctx, migrator, os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: migrate up")
+	fmt.Fprintln(os.Stderr, "       migrate down [-steps N]")
+	fmt.Fprintln(os.Stderr, "       migrate status")
+	fmt.Fprintln(os.Stderr, "       migrate baseline VERSION")
+	os.Exit(2)
+}
+
+func runUp(ctx context.Context, migrator *migrations.Migrator) {
+	applied, err := migrator.Up(ctx)
+	for _, m := range applied {
+		fmt.Printf("applied %04d_%s\n", m.Version, m.Name)
+	}
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+	if len(applied) == 0 {
+		fmt.Println("database is up to date")
+	}
+}
+
+func runDown(ctx context.Context, migrator *migrations.Migrator, args []string) {
+	fs := flag.NewFlagSet("down", flag.ExitOnError)
+	steps := fs.Int("steps", 1, "number of migrations to roll back")
+	fs.Parse(args)
+
+	reverted, err := migrator.Down(ctx, *steps)
+	for _, m := range reverted {
+		fmt.Printf("rolled back %04d_%s\n", m.Version, m.Name)
+	}
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+	if len(reverted) == 0 {
+		fmt.Println("nothing to roll back")
+	}
+}
+
+func runBaseline(ctx context.Context, migrator *migrations.Migrator, args []string) {
+	if len(args) != 1 {
+		usage()
+	}
+	version, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		log.Fatalf("FATAL: invalid version %q", args[0])
+	}
+
+	marked, err := migrator.Baseline(ctx, version)
+	for _, m := range marked {
+		fmt.Printf("marked %04d_%s as applied\n", m.Version, m.Name)
+	}
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+	if len(marked) == 0 {
+		fmt.Println("nothing to mark")
+	}
+}
+
+func runStatus(ctx context.Context, migrator *migrations.Migrator) {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT")
+	pending := 0
+	for _, s := range statuses {
+		appliedAt := "pending"
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Format(time.RFC3339)
+		} else {
+			pending++
+		}
+		fmt.Fprintf(tw, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+	}
+	tw.Flush()
+	fmt.Printf("\n%d of %d migrations pending\n", pending, len(statuses))
+}
@@ -0,0 +1,157 @@
+// Command otelstub adalah collector OTLP/HTTP palsu untuk pengembangan dan pengujian lokal.
+// Span yang diterima dicetak ke log dan disimpan di memori agar bisa diperiksa kembali.
+//
+// Penggunaan:
+//
+//	otelstub [-addr :4318]
+//
+// Lalu jalankan auth-service dengan TRACING_ENABLED=true dan
+// TRACING_ENDPOINT=http://localhost:4318/v1/traces. Span bisa dibaca melalui
+// GET /spans?trace_id=<hex>.
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+type span struct {
+	TraceID      string            `json:"trace_id"`
+	SpanID       string            `json:"span_id"`
+	ParentSpanID string            `json:"parent_span_id,omitempty"`
+	Service      string            `json:"service"`
+	Name         string            `json:"name"`
+	Duration     string            `json:"duration"`
+	Status       string            `json:"status,omitempty"`
+	Attributes   map[string]string `json:"attributes,omitempty"`
+}
+
+type store struct {
+	mu    sync.Mutex
+	spans []span
+}
+
+func main() {
+	addr := flag.String("addr", ":4318", "alamat listen")
+	flag.Parse()
+
+	s := &store{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/traces", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "could not read body", http.StatusBadRequest)
+			return
+		}
+
+		var req coltracepb.ExportTraceServiceRequest
+		if r.Header.Get("Content-Type") == "application/json" {
+			err = protojson.Unmarshal(body, &req)
+		} else {
+			err = proto.Unmarshal(body, &req)
+		}
+		if err != nil {
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+
+		received := flatten(&req)
+		s.mu.Lock()
+		s.spans = append(s.spans, received...)
+		s.mu.Unlock()
+		for _, sp := range received {
+			log.Printf("%s %s %s (%s)", sp.TraceID, sp.Service, sp.Name, sp.Duration)
+		}
+
+		resp, _ := proto.Marshal(&coltracepb.ExportTraceServiceResponse{})
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.Write(resp)
+	})
+	mux.HandleFunc("GET /spans", func(w http.ResponseWriter, r *http.Request) {
+		traceID := r.URL.Query().Get("trace_id")
+
+		s.mu.Lock()
+		result := []span{}
+		for _, sp := range s.spans {
+			if traceID == "" || sp.TraceID == traceID {
+				result = append(result, sp)
+			}
+		}
+		s.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	})
+	mux.HandleFunc("DELETE /spans", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.spans = nil
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	log.Printf("otelstub listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
+
+func flatten(req *coltracepb.ExportTraceServiceRequest) []span {
+	var result []span
+	for _, rs := range req.GetResourceSpans() {
+		service := ""
+		for _, attr := range rs.GetResource().GetAttributes() {
+			if attr.GetKey() == "service.name" {
+				service = attr.GetValue().GetStringValue()
+			}
+		}
+		for _, ss := range rs.GetScopeSpans() {
+			for _, sp := range ss.GetSpans() {
+				attrs := make(map[string]string)
+				for _, attr := range sp.GetAttributes() {
+					attrs[attr.GetKey()] = attributeString(attr.GetValue())
+				}
+				item := span{
+					TraceID:    hex.EncodeToString(sp.GetTraceId()),
+					SpanID:     hex.EncodeToString(sp.GetSpanId()),
+					Service:    service,
+					Name:       sp.GetName(),
+					Duration:   time.Duration(sp.GetEndTimeUnixNano() - sp.GetStartTimeUnixNano()).String(),
+					Attributes: attrs,
+				}
+				if len(sp.GetParentSpanId()) > 0 {
+					item.ParentSpanID = hex.EncodeToString(sp.GetParentSpanId())
+				}
+				if msg := sp.GetStatus().GetMessage(); msg != "" {
+					item.Status = msg
+				}
+				result = append(result, item)
+			}
+		}
+	}
+	return result
+}
+
+func attributeString(v *commonpb.AnyValue) string {
+	switch value := v.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return value.StringValue
+	case *commonpb.AnyValue_IntValue:
+		return strconv.FormatInt(value.IntValue, 10)
+	case *commonpb.AnyValue_BoolValue:
+		return strconv.FormatBool(value.BoolValue)
+	case *commonpb.AnyValue_DoubleValue:
+		return strconv.FormatFloat(value.DoubleValue, 'f', -1, 64)
+	default:
+		return protojson.Format(v)
+	}
+}
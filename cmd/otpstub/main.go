@@ -0,0 +1,85 @@
+// Command otpstub adalah provider SMS/WhatsApp palsu untuk pengembangan dan pengujian lokal.
+// Pesan yang diterima disimpan di memori dan bisa dibaca kembali, sehingga OTP dapat
+// diambil tanpa gateway sungguhan.
+//
+// Penggunaan:
+//
+//	otpstub [-addr :9090] [-token TOKEN]
+//
+// Lalu jalankan auth-service dengan SMS_PROVIDER=http dan SMS_HTTP_URL=http://localhost:9090/send
+// (atau WHATSAPP_PROVIDER / WHATSAPP_HTTP_URL). Pesan bisa dibaca melalui GET /messages?to=+62...
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type message struct {
+	Channel    string    `json:"channel"`
+	To         string    `json:"to"`
+	Message    string    `json:"message"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+type store struct {
+	mu       sync.Mutex
+	messages []message
+}
+
+func main() {
+	addr := flag.String("addr", ":9090", "alamat listen")
+	token := flag.String("token", "", "bearer token yang diwajibkan (opsional)")
+	flag.Parse()
+
+	s := &store{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /send", func(w http.ResponseWriter, r *http.Request) {
+		if *token != "" && r.Header.Get("Authorization") != "Bearer "+*token {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var msg message
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil || msg.To == "" {
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+		msg.ReceivedAt = time.Now().UTC()
+
+		s.mu.Lock()
+		s.messages = append(s.messages, msg)
+		s.mu.Unlock()
+
+		log.Printf("[%s] %s: %s", msg.Channel, msg.To, msg.Message)
+		w.WriteHeader(http.StatusAccepted)
+	})
+	mux.HandleFunc("GET /messages", func(w http.ResponseWriter, r *http.Request) {
+		to := r.URL.Query().Get("to")
+
+		s.mu.Lock()
+		result := []message{}
+		for _, msg := range s.messages {
+			if to == "" || msg.To == to {
+				result = append(result, msg)
+			}
+		}
+		s.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	})
+	mux.HandleFunc("DELETE /messages", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.messages = nil
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	log.Printf("otpstub listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
@@ -1,11 +1,24 @@
+// Package config memuat konfigurasi aplikasi secara berlapis. Urutan prioritas dari yang
+// paling lemah: nilai default, file konfigurasi (YAML atau TOML), environment variable
+// (termasuk .env), lalu flag.
+//
+// Setiap field memiliki satu nama dasar pada tag env, misalnya ACCESS_TOKEN_DURATION.
+// Di file konfigurasi nama tersebut ditulis huruf kecil (access_token_duration: 15m) dan
+// sebagai flag ditulis dengan tanda hubung (--access-token-duration=15m). Field yang
+// bertanda secret juga dapat dibaca dari file lewat varian _FILE, misalnya JWT_SECRET_FILE,
+// jwt_secret_file, atau --jwt-secret-file. Field bertanda reload dapat dimuat ulang tanpa
+// restart lewat Reloader; pemakainya harus membaca nilai tersebut dari Current().
 package config
 
 import (
+	"auth-service/metrics"
 	"context"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
-	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -15,85 +28,200 @@ import (
 	"gorm.io/gorm"
 )
 
+// Storage driver. StorageMemory menyimpan semua data di memori proses tanpa Postgres dan
+// Redis; hanya untuk pengembangan lokal karena data hilang saat server berhenti.
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+)
+
 type Config struct {
-	Port                       string
-	DB                         *gorm.DB      `validate:"-"`
-	Redis                      *redis.Client `validate:"-"`
-	JwtSecret                  string        `validate:"required"`
-	SmtpHost                   string        `validate:"required"`
-	SmtpPort                   string        `validate:"required"`
-	SmtpUser                   string        `validate:"required"`
-	SmtpPassword               string        `validate:"required"`
-	AppEmail                   string        `validate:"required,email"`
-	AccessTokenDuration        time.Duration
-	RefreshTokenDuration       time.Duration
-	OTPDuration                time.Duration
-	ResetPasswordTokenDuration time.Duration
-}
+	ConfigFile                string        `validate:"-"`
+	ConfigWatchInterval       time.Duration `env:"CONFIG_WATCH_INTERVAL" default:"10s" validate:"gte=0"`
+	Port                      string        `env:"APP_PORT" default:"8080"`
+	MetricsAddr               string        `env:"METRICS_ADDR" default:"127.0.0.1:9090" validate:"omitempty,hostname_port"`
+	LogLevel                  string        `env:"LOG_LEVEL" reload:"true" default:"info" validate:"oneof=debug info warn error"`
+	LogFormat                 string        `env:"LOG_FORMAT" default:"json" validate:"oneof=json text"`
+	TracingEnabled            bool          `env:"TRACING_ENABLED" default:"false"`
+	TracingEndpoint           string        `env:"TRACING_ENDPOINT" validate:"omitempty,url"`
+	TracingServiceName        string        `env:"OTEL_SERVICE_NAME" default:"auth-service" validate:"required"`
+	TracingSampleRatio        float64       `env:"TRACING_SAMPLE_RATIO" default:"1" validate:"gte=0,lte=1"`
+	AppName                   string        `env:"APP_NAME" default:"Auth Service" validate:"required"`
+	StorageDriver             string        `env:"STORAGE_DRIVER" default:"postgres" validate:"oneof=postgres memory"`
+	DBHost                    string        `env:"DB_HOST"`
+	DBPort                    string        `env:"DB_PORT"`
+	DBUser                    string        `env:"DB_USER"`
+	DBPassword                string        `env:"DB_PASSWORD" secret:"true"`
+	DBName                    string        `env:"DB_NAME"`
+	DBSSLMode                 string        `env:"DB_SSLMODE"`
+	RedisAddr                 string        `env:"REDIS_ADDR"`
+	RedisPassword             string        `env:"REDIS_PASSWORD" secret:"true"`
+	RedisDB                   int           `env:"REDIS_DB" default:"0" validate:"min=0"`
+	DB                        *gorm.DB      `validate:"-"`
+	Redis                     *redis.Client `validate:"-"`
+	JwtSecret                 string        `env:"JWT_SECRET" secret:"true" validate:"required"`
+	AuditHMACKey              string        `env:"AUDIT_HMAC_KEY" secret:"true" validate:"required,min=32"`
+	JwtPreviousSecrets        []string      `env:"JWT_PREVIOUS_SECRETS" secret:"true"`
+	MailDriver                string        `env:"MAIL_DRIVER" default:"smtp" validate:"oneof=smtp file log"`
+	MailFileDir               string        `env:"MAIL_FILE_DIR" default:"./tmp/mail" validate:"required_if=MailDriver file"`
+	MailQueueEnabled          bool          `env:"MAIL_QUEUE_ENABLED" default:"true"`
+	MailWorkers               int           `env:"MAIL_WORKERS" default:"4" validate:"min=1"`
+	MailMaxAttempts           int           `env:"MAIL_MAX_ATTEMPTS" default:"5" validate:"min=1"`
+	MailTemplateDir           string        `env:"MAIL_TEMPLATE_DIR" reload:"true"`
+	MailDefaultLocale         string        `env:"MAIL_DEFAULT_LOCALE" default:"id" validate:"required"`
+	SmtpHost                  string        `env:"MAIL_HOST" validate:"required_if=MailDriver smtp"`
+	SmtpPort                  string        `env:"MAIL_PORT" validate:"required_if=MailDriver smtp"`
+	SmtpUser                  string        `env:"MAIL_USERNAME"`
+	SmtpPassword              string        `env:"MAIL_PASSWORD" secret:"true" validate:"required_with=SmtpUser"`
+	SmtpTLSMode               string        `env:"MAIL_TLS_MODE" default:"starttls" validate:"oneof=implicit starttls none"`
+	SmtpSTARTTLSPolicy        string        `env:"MAIL_STARTTLS_POLICY" default:"opportunistic" validate:"oneof=required opportunistic"`
+	SmtpCAFile                string        `env:"MAIL_CA_FILE" validate:"omitempty,file"`
+	SmtpTimeout               time.Duration `env:"MAIL_TIMEOUT" legacy:"MAIL_TIMEOUT_SECONDS" default:"30s" validate:"gt=0"`
+	SmtpPoolSize              int           `env:"MAIL_POOL_SIZE" default:"2" validate:"min=0"`
+	SmtpPoolIdleTimeout       time.Duration `env:"MAIL_POOL_IDLE_TIMEOUT" legacy:"MAIL_POOL_IDLE_TIMEOUT_SECONDS" default:"30s"`
+	AppEmail                  string        `env:"MAIL_FROM" validate:"required,email"`
+	MailFromName              string        `env:"MAIL_FROM_NAME"`
+	DKIMPrivateKeyFile        string        `env:"DKIM_PRIVATE_KEY_FILE" validate:"omitempty,file"`
+	DKIMDomain                string        `env:"DKIM_DOMAIN" validate:"required_with=DKIMPrivateKeyFile"`
+	DKIMSelector              string        `env:"DKIM_SELECTOR" validate:"required_with=DKIMPrivateKeyFile"`
+	SMSProvider               string        `env:"SMS_PROVIDER" validate:"omitempty,oneof=http twilio"`
+	SMSHTTPURL                string        `env:"SMS_HTTP_URL" validate:"required_if=SMSProvider http,omitempty,url"`
+	SMSHTTPToken              string        `env:"SMS_HTTP_TOKEN" secret:"true"`
+	WhatsAppProvider          string        `env:"WHATSAPP_PROVIDER" validate:"omitempty,oneof=http twilio"`
+	WhatsAppHTTPURL           string        `env:"WHATSAPP_HTTP_URL" validate:"required_if=WhatsAppProvider http,omitempty,url"`
+	WhatsAppHTTPToken         string        `env:"WHATSAPP_HTTP_TOKEN" secret:"true"`
+	TwilioAccountSID          string        `env:"TWILIO_ACCOUNT_SID" validate:"required_if=SMSProvider twilio,required_if=WhatsAppProvider twilio"`
+	TwilioAuthToken           string        `env:"TWILIO_AUTH_TOKEN" secret:"true" validate:"required_if=SMSProvider twilio,required_if=WhatsAppProvider twilio"`
+	TwilioSMSFrom             string        `env:"TWILIO_SMS_FROM" validate:"required_if=SMSProvider twilio"`
+	TwilioWhatsAppFrom        string        `env:"TWILIO_WHATSAPP_FROM" validate:"required_if=WhatsAppProvider twilio"`
+	OTPProviderTimeout        time.Duration `env:"OTP_PROVIDER_TIMEOUT" legacy:"OTP_PROVIDER_TIMEOUT_SECONDS" default:"10s"`
+	AppBaseURL                string        `env:"APP_BASE_URL" validate:"required_unless=EmailVerificationMode otp,omitempty,url"`
+	EmailVerificationMode     string        `env:"EMAIL_VERIFICATION_MODE" reload:"true" default:"otp" validate:"oneof=otp link both"`
+	EmailVerificationRedirect string        `env:"EMAIL_VERIFICATION_REDIRECT_URL" validate:"omitempty,url"`
+	EmailVerificationDuration time.Duration `env:"EMAIL_VERIFICATION_LINK_DURATION" reload:"true" legacy:"EMAIL_VERIFICATION_LINK_DURATION_HOURS" default:"24h"`
+	// PasswordResetURL adalah template tautan reset password di frontend, misalnya
+	// "https://app.example.com/reset-password?token={token}". PasswordResetClientURLs
+	// berisi template per client_id yang dipilih lewat ForgotPasswordInput.ClientID.
+	PasswordResetURL           string            `env:"PASSWORD_RESET_URL" reload:"true" validate:"omitempty,contains={token}"`
+	PasswordResetClientURLs    map[string]string `env:"PASSWORD_RESET_CLIENT_URLS" reload:"true" validate:"dive,contains={token}"`
+	AccessTokenDuration        time.Duration     `env:"ACCESS_TOKEN_DURATION" reload:"true" legacy:"ACCESS_TOKEN_DURATION_MINUTES" default:"15m"`
+	RefreshTokenDuration       time.Duration     `env:"REFRESH_TOKEN_DURATION" reload:"true" legacy:"REFRESH_TOKEN_DURATION_HOURS" default:"168h"`
+	OTPDuration                time.Duration     `env:"OTP_DURATION" reload:"true" legacy:"OTP_DURATION_MINUTES" default:"5m"`
+	OTPResendCooldown          time.Duration     `env:"OTP_RESEND_COOLDOWN" reload:"true" default:"60s" validate:"gte=0"`
+	PhoneOTPDailyLimit         int               `env:"PHONE_OTP_DAILY_LIMIT" reload:"true" default:"5" validate:"min=1"`
+	ResetPasswordTokenDuration time.Duration     `env:"RESET_TOKEN_DURATION" reload:"true" legacy:"RESET_TOKEN_DURATION_MINUTES" default:"15m"`
+	ImpersonationTokenDuration time.Duration     `env:"IMPERSONATION_TOKEN_DURATION" reload:"true" legacy:"IMPERSONATION_TOKEN_DURATION_MINUTES" default:"15m"`
+	WebhookEndpoints           []string          `env:"WEBHOOK_ENDPOINTS" validate:"dive,url"`
+	WebhookSecret              string            `env:"WEBHOOK_SECRET" secret:"true" validate:"required_with=WebhookEndpoints"`
+	WebhookTimeout             time.Duration     `env:"WEBHOOK_TIMEOUT" legacy:"WEBHOOK_TIMEOUT_SECONDS" default:"10s"`
+	OutboxPollInterval         time.Duration     `env:"OUTBOX_POLL_INTERVAL" legacy:"OUTBOX_POLL_INTERVAL_SECONDS" default:"2s" validate:"gt=0"`
+	OutboxBatchSize            int               `env:"OUTBOX_BATCH_SIZE" default:"50" validate:"min=1"`
+	OutboxMaxAttempts          int               `env:"OUTBOX_MAX_ATTEMPTS" default:"10" validate:"min=1"`
+	OutboxRetention            time.Duration     `env:"OUTBOX_RETENTION" default:"168h" validate:"gt=0"`
+	MigrateOnStart             bool              `env:"MIGRATE_ON_START" default:"false"`
+	HealthCheckTimeout         time.Duration     `env:"HEALTH_CHECK_TIMEOUT" legacy:"HEALTH_CHECK_TIMEOUT_SECONDS" default:"2s" validate:"gt=0"`
+	HTTPReadHeaderTimeout      time.Duration     `env:"HTTP_READ_HEADER_TIMEOUT" legacy:"HTTP_READ_HEADER_TIMEOUT_SECONDS" default:"5s" validate:"gt=0"`
+	HTTPReadTimeout            time.Duration     `env:"HTTP_READ_TIMEOUT" legacy:"HTTP_READ_TIMEOUT_SECONDS" default:"15s" validate:"gte=0"`
+	HTTPWriteTimeout           time.Duration     `env:"HTTP_WRITE_TIMEOUT" legacy:"HTTP_WRITE_TIMEOUT_SECONDS" default:"30s" validate:"gte=0"`
+	HTTPIdleTimeout            time.Duration     `env:"HTTP_IDLE_TIMEOUT" legacy:"HTTP_IDLE_TIMEOUT_SECONDS" default:"60s" validate:"gte=0"`
+	ShutdownTimeout            time.Duration     `env:"SHUTDOWN_TIMEOUT" legacy:"SHUTDOWN_TIMEOUT_SECONDS" default:"30s" validate:"gt=0"`
+	ShutdownDrainDelay         time.Duration     `env:"SHUTDOWN_DRAIN_DELAY" default:"5s" validate:"gte=0"`
+	HealthCheckSMTP            bool              `env:"HEALTH_CHECK_SMTP" default:"false"`
 
-func parseIntWithDefault(strVal string, defaultVal int) int {
-	if val, err := strconv.Atoi(strVal); err == nil {
-		return val
-	}
-	return defaultVal
+	live *atomic.Pointer[Config]
 }
 
-func LoadConfig() (*Config, error) {
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, using system environment variables")
+// Current mengembalikan konfigurasi terbaru setelah reload. Setting bertanda reload harus
+// dibaca lewat Current(); setting lain tidak pernah berubah sehingga boleh dibaca langsung.
+func (c *Config) Current() *Config {
+	if c.live == nil {
+		return c
 	}
+	return c.live.Load()
+}
 
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbUser := os.Getenv("DB_USER")
-	dbPassword := os.Getenv("DB_PASSWORD")
-	dbName := os.Getenv("DB_NAME")
-	dbSSLMode := os.Getenv("DB_SSLMODE")
+// JWTVerificationSecrets mengembalikan secret aktif diikuti secret lama yang masih diterima
+// saat memverifikasi token. Token baru selalu ditandatangani dengan JwtSecret.
+func (c *Config) JWTVerificationSecrets() []string {
+	return append([]string{c.JwtSecret}, c.JwtPreviousSecrets...)
+}
 
-	dsn := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		dbHost, dbPort, dbUser, dbPassword, dbName, dbSSLMode,
-	)
+// parseList memecah nilai yang dipisahkan koma atau baris baru dan membuang elemen kosong.
+func parseList(strVal string) []string {
+	var result []string
+	for _, item := range strings.FieldsFunc(strVal, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
+// parseMap memecah nilai "key=value" yang dipisahkan koma.
+func parseMap(strVal string) map[string]string {
+	result := make(map[string]string)
+	for _, item := range parseList(strVal) {
+		key, value, ok := strings.Cut(item, "=")
+		if !ok {
+			continue
+		}
+		result[strings.TrimSpace(key)] = strings.TrimSpace(value)
 	}
+	return result
+}
 
-	redisClient := redis.NewClient(&redis.Options{
-		Addr:     os.Getenv("REDIS_ADDR"),
-		Password: os.Getenv("REDIS_PASSWORD"),
-		DB:       0,
-	})
+// Load membaca dan memvalidasi konfigurasi tanpa membuka koneksi apa pun. Flag konfigurasi
+// didaftarkan ke fs sehingga pemanggil dapat menambahkan flag miliknya sendiri; jika fs nil,
+// hanya default, file dari CONFIG_FILE, dan environment yang dipakai.
+func Load(fs *flag.FlagSet, args []string) (*Config, error) {
+	if err := godotenv.Load(); err != nil {
+		slog.Info("no .env file found, using system environment variables")
+	}
 
-	if _, err := redisClient.Ping(context.Background()).Result(); err != nil {
-		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	configFile := os.Getenv("CONFIG_FILE")
+	var flagValues map[string]string
+	if fs != nil {
+		fs.StringVar(&configFile, "config", configFile, "path to a YAML or TOML config file (env CONFIG_FILE)")
+		flagValues = registerFlags(fs)
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
 	}
 
-	port := os.Getenv("APP_PORT")
-	if port == "" {
-		port = "8080"
+	layers := []map[string]string{defaultValues()}
+	if configFile != "" {
+		fileValues, err := readConfigFile(configFile)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, fileValues)
+	}
+	envValues, err := readEnv()
+	if err != nil {
+		return nil, err
 	}
+	layers = append(layers, envValues, flagValues)
 
-	accessTokenMin := parseIntWithDefault(os.Getenv("ACCESS_TOKEN_DURATION_MINUTES"), 15)
-	refreshTokenHours := parseIntWithDefault(os.Getenv("REFRESH_TOKEN_DURATION_HOURS"), 168) // 7 days
-	otpMin := parseIntWithDefault(os.Getenv("OTP_DURATION_MINUTES"), 5)
-	resetTokenMin := parseIntWithDefault(os.Getenv("RESET_TOKEN_DURATION_MINUTES"), 15)
+	values := make(map[string]string)
+	for _, layer := range layers {
+		if err := resolveSecretFiles(layer); err != nil {
+			return nil, err
+		}
+		for key, value := range layer {
+			values[key] = value
+		}
+	}
 
-	cfg := &Config{
-		Port:                       port,
-		DB:                         db,
-		Redis:                      redisClient,
-		JwtSecret:                  os.Getenv("JWT_SECRET"),
-		SmtpHost:                   os.Getenv("MAIL_HOST"),
-		SmtpPort:                   os.Getenv("MAIL_PORT"),
-		SmtpUser:                   os.Getenv("MAIL_USERNAME"),
-		SmtpPassword:               os.Getenv("MAIL_PASSWORD"),
-		AppEmail:                   os.Getenv("MAIL_FROM"),
-		AccessTokenDuration:        time.Duration(accessTokenMin) * time.Minute,
-		RefreshTokenDuration:       time.Duration(refreshTokenHours) * time.Hour,
-		OTPDuration:                time.Duration(otpMin) * time.Minute,
-		ResetPasswordTokenDuration: time.Duration(resetTokenMin) * time.Minute,
+	cfg := &Config{ConfigFile: configFile, live: new(atomic.Pointer[Config])}
+	cfg.live.Store(cfg)
+	if err := cfg.apply(values); err != nil {
+		return nil, err
+	}
+	cfg.LogLevel = strings.ToLower(cfg.LogLevel)
+	cfg.LogFormat = strings.ToLower(cfg.LogFormat)
+	cfg.AppBaseURL = strings.TrimRight(cfg.AppBaseURL, "/")
+	if cfg.MailFromName == "" {
+		cfg.MailFromName = cfg.AppName
 	}
 
 	validate := validator.New()
@@ -101,6 +229,58 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("configuration validation error: %w", err)
 	}
 
-	log.Println("✅ Configuration loaded successfully")
+	slog.Info("configuration loaded", "file", configFile)
+	return cfg, nil
+}
+
+// LoadConfig memuat konfigurasi tanpa flag lalu membuka koneksi database dan Redis.
+// Dipakai oleh command yang argumennya bukan flag konfigurasi.
+func LoadConfig() (*Config, error) {
+	cfg, err := Load(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.StorageDriver == StorageMemory {
+		return nil, fmt.Errorf("STORAGE_DRIVER=%s is only supported by the server", StorageMemory)
+	}
+	if err := cfg.Connect(context.Background()); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
+
+// Connect membuka koneksi Postgres dan Redis lalu mengisi c.DB dan c.Redis. Dengan
+// STORAGE_DRIVER=memory tidak ada koneksi yang dibuka dan keduanya tetap nil.
+func (c *Config) Connect(ctx context.Context) error {
+	if c.StorageDriver == StorageMemory {
+		return nil
+	}
+
+	dsn := fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		c.DBHost, c.DBPort, c.DBUser, c.DBPassword, c.DBName, c.DBSSLMode,
+	)
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	if err := metrics.InstrumentGORM(db); err != nil {
+		return fmt.Errorf("failed to instrument database: %w", err)
+	}
+
+	redisClient := redis.NewClient(&redis.Options{
+		Addr:     c.RedisAddr,
+		Password: c.RedisPassword,
+		DB:       c.RedisDB,
+	})
+	redisClient.AddHook(metrics.RedisHook{})
+
+	if _, err := redisClient.Ping(ctx).Result(); err != nil {
+		return fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	c.DB = db
+	c.Redis = redisClient
+	return nil
+}
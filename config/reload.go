@@ -0,0 +1,144 @@
+package config
+
+import (
+	"auth-service/metrics"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Reloader memuat ulang setting bertanda reload saat menerima SIGHUP atau saat file
+// konfigurasi berubah. Konfigurasi baru divalidasi penuh sebelum dipasang; jika ada yang
+// gagal, konfigurasi lama tetap dipakai. Perubahan pada setting lain hanya dicatat sebagai
+// peringatan karena baru berlaku setelah restart.
+type Reloader struct {
+	cfg   *Config
+	args  []string
+	hooks []func(next *Config) error
+	mu    sync.Mutex
+}
+
+// NewReloader membuat Reloader untuk cfg. args adalah flag yang sama dengan saat cfg dimuat
+// agar nilai dari flag tetap berlaku setelah reload.
+func NewReloader(cfg *Config, args []string) *Reloader {
+	return &Reloader{cfg: cfg, args: args}
+}
+
+// OnReload mendaftarkan fungsi yang dipanggil dengan konfigurasi baru sebelum dipasang,
+// misalnya untuk memuat ulang template. Jika fungsi mengembalikan error, reload dibatalkan.
+func (r *Reloader) OnReload(fn func(next *Config) error) {
+	r.hooks = append(r.hooks, fn)
+}
+
+// Reload memuat konfigurasi dari semua sumber dan memasang setting bertanda reload yang berubah.
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fs := flag.NewFlagSet("reload", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	loaded, err := Load(fs, r.args)
+	if err != nil {
+		return err
+	}
+
+	current := r.cfg.Current()
+	next := *current
+	currentValue := reflect.ValueOf(current).Elem()
+	loadedValue := reflect.ValueOf(loaded).Elem()
+	nextValue := reflect.ValueOf(&next).Elem()
+
+	var changed, restartRequired []string
+	for _, s := range settings {
+		if reflect.DeepEqual(currentValue.Field(s.index).Interface(), loadedValue.Field(s.index).Interface()) {
+			continue
+		}
+		if !s.reload {
+			restartRequired = append(restartRequired, s.key())
+			continue
+		}
+		nextValue.Field(s.index).Set(loadedValue.Field(s.index))
+		changed = append(changed, s.key())
+	}
+
+	// Gabungan setting lama dan baru divalidasi ulang karena ada aturan lintas field,
+	// misalnya APP_BASE_URL yang wajib jika EMAIL_VERIFICATION_MODE bukan otp.
+	if err := validator.New().Struct(&next); err != nil {
+		return fmt.Errorf("configuration validation error: %w", err)
+	}
+	for _, hook := range r.hooks {
+		if err := hook(&next); err != nil {
+			return err
+		}
+	}
+
+	r.cfg.live.Store(&next)
+	slog.Info("configuration reloaded", "changed", changed)
+	if len(restartRequired) > 0 {
+		slog.Warn("configuration changes ignored until restart", "settings", restartRequired)
+	}
+	return nil
+}
+
+// Run menunggu SIGHUP dan memeriksa file konfigurasi setiap CONFIG_WATCH_INTERVAL sampai
+// ctx selesai. Pemeriksaan file dimatikan jika tidak ada file konfigurasi atau interval 0.
+func (r *Reloader) Run(ctx context.Context) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	var tick <-chan time.Time
+	path := r.cfg.ConfigFile
+	if path != "" && r.cfg.ConfigWatchInterval > 0 {
+		ticker := time.NewTicker(r.cfg.ConfigWatchInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	version := fileVersion(path)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			r.reloadAndRecord("signal")
+		case <-tick:
+			// Versi dicatat sebelum reload agar file yang tidak valid tidak dicoba terus-menerus.
+			if v := fileVersion(path); v != version {
+				version = v
+				r.reloadAndRecord("file")
+			}
+		}
+	}
+}
+
+func (r *Reloader) reloadAndRecord(trigger string) {
+	outcome := metrics.OutcomeSuccess
+	if err := r.Reload(); err != nil {
+		outcome = "error"
+		slog.Error("configuration reload failed, keeping current configuration", "trigger", trigger, "error", err)
+	}
+	metrics.ConfigReloads.WithLabelValues(trigger, outcome).Inc()
+}
+
+// fileVersion mengembalikan penanda perubahan file berupa waktu modifikasi dan ukurannya.
+func fileVersion(path string) string {
+	if path == "" {
+		return ""
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size())
+}
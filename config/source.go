@@ -0,0 +1,316 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// setting adalah satu field Config yang dapat diatur dari luar, dibaca dari tag struct.
+type setting struct {
+	index  int
+	env    string
+	legacy string // nama env lama berbasis satuan (misalnya _MINUTES) yang masih diterima
+	def    string
+	hasDef bool
+	secret bool
+	reload bool
+}
+
+// key adalah nama setting di file konfigurasi.
+func (s setting) key() string {
+	return strings.ToLower(s.env)
+}
+
+// flagName adalah nama setting sebagai flag.
+func (s setting) flagName() string {
+	return strings.ReplaceAll(s.key(), "_", "-")
+}
+
+var settings = loadSettings()
+
+func loadSettings() []setting {
+	t := reflect.TypeOf(Config{})
+	var result []setting
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		env, ok := field.Tag.Lookup("env")
+		if !ok {
+			continue
+		}
+		def, hasDef := field.Tag.Lookup("default")
+		result = append(result, setting{
+			index:  i,
+			env:    env,
+			legacy: field.Tag.Get("legacy"),
+			def:    def,
+			hasDef: hasDef,
+			secret: field.Tag.Get("secret") == "true",
+			reload: field.Tag.Get("reload") == "true",
+		})
+	}
+	return result
+}
+
+func defaultValues() map[string]string {
+	values := make(map[string]string)
+	for _, s := range settings {
+		if s.hasDef {
+			values[s.env] = s.def
+		}
+	}
+	return values
+}
+
+// readEnv membaca environment variable. Nilai kosong dianggap tidak diatur.
+func readEnv() (map[string]string, error) {
+	values := make(map[string]string)
+	for _, s := range settings {
+		if value := os.Getenv(s.env); value != "" {
+			values[s.env] = value
+		} else if value := os.Getenv(s.legacy); s.legacy != "" && value != "" {
+			duration, err := legacyDuration(s.legacy, value)
+			if err != nil {
+				return nil, err
+			}
+			values[s.env] = duration
+		}
+		if path := os.Getenv(s.env + "_FILE"); s.secret && path != "" {
+			values[s.env+"_FILE"] = path
+		}
+	}
+	return values, nil
+}
+
+// legacyDuration mengubah nilai env lama seperti ACCESS_TOKEN_DURATION_MINUTES=15 menjadi "15m0s".
+func legacyDuration(name, value string) (string, error) {
+	var unit time.Duration
+	switch {
+	case strings.HasSuffix(name, "_SECONDS"):
+		unit = time.Second
+	case strings.HasSuffix(name, "_MINUTES"):
+		unit = time.Minute
+	case strings.HasSuffix(name, "_HOURS"):
+		unit = time.Hour
+	default:
+		return "", fmt.Errorf("unknown unit for %s", name)
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid value %q for %s: %w", value, name, err)
+	}
+	return (time.Duration(n) * unit).String(), nil
+}
+
+// readConfigFile membaca file YAML atau TOML berdasarkan ekstensinya. Key yang tidak
+// dikenal ditolak agar salah ketik tidak diam-diam diabaikan.
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read config file: %w", err)
+	}
+
+	raw := make(map[string]interface{})
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return nil, fmt.Errorf("unsupported config file format %q (use .yaml, .yml or .toml)", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not parse config file %s: %w", path, err)
+	}
+
+	known := make(map[string]bool)
+	for _, s := range settings {
+		known[s.key()] = true
+		if s.secret {
+			known[s.key()+"_file"] = true
+		}
+	}
+
+	values := make(map[string]string)
+	for key, value := range raw {
+		if !known[key] {
+			return nil, fmt.Errorf("unknown key %q in config file %s", key, path)
+		}
+		values[strings.ToUpper(key)] = fileValue(value)
+	}
+	return values, nil
+}
+
+// fileValue mengubah nilai dari file ke bentuk string yang sama dengan environment:
+// list menjadi "a,b" dan map menjadi "k=v,k2=v2".
+func fileValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fileValue(item)
+		}
+		return strings.Join(items, ",")
+	case map[string]interface{}:
+		items := make([]string, 0, len(v))
+		for key, item := range v {
+			items = append(items, key+"="+fileValue(item))
+		}
+		sort.Strings(items)
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// flagValue mencatat nilai flag ke layer flag hanya jika flag tersebut diberikan.
+type flagValue struct {
+	values map[string]string
+	key    string
+	isBool bool
+}
+
+func (f *flagValue) String() string   { return "" }
+func (f *flagValue) IsBoolFlag() bool { return f.isBool }
+
+func (f *flagValue) Set(value string) error {
+	f.values[f.key] = value
+	return nil
+}
+
+func registerFlags(fs *flag.FlagSet) map[string]string {
+	values := make(map[string]string)
+	configType := reflect.TypeOf(Config{})
+	for _, s := range settings {
+		usage := "overrides " + s.env
+		if s.hasDef {
+			usage += " (default " + s.def + ")"
+		}
+		isBool := configType.Field(s.index).Type.Kind() == reflect.Bool
+		fs.Var(&flagValue{values: values, key: s.env, isBool: isBool}, s.flagName(), usage)
+		if s.secret {
+			fs.Var(&flagValue{values: values, key: s.env + "_FILE"}, s.flagName()+"-file", "reads "+s.env+" from a file")
+		}
+	}
+	return values
+}
+
+// resolveSecretFiles mengganti varian _FILE dalam satu layer dengan isi file yang dirujuk.
+func resolveSecretFiles(layer map[string]string) error {
+	for _, s := range settings {
+		path, ok := layer[s.env+"_FILE"]
+		if !ok || !s.secret {
+			continue
+		}
+		delete(layer, s.env+"_FILE")
+		if _, ok := layer[s.env]; ok {
+			return fmt.Errorf("%s and %s_FILE are both set", s.env, s.env)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("could not read %s_FILE: %w", s.env, err)
+		}
+		layer[s.env] = strings.TrimRight(string(data), "\r\n")
+	}
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func (c *Config) apply(values map[string]string) error {
+	v := reflect.ValueOf(c).Elem()
+	for _, s := range settings {
+		raw, ok := values[s.env]
+		if !ok {
+			continue
+		}
+		if err := setField(v.Field(s.index), raw); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", s.env, err)
+		}
+	}
+	return nil
+}
+
+func setField(field reflect.Value, raw string) error {
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		field.Set(reflect.ValueOf(parseList(raw)))
+	case reflect.Map:
+		field.Set(reflect.ValueOf(parseMap(raw)))
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+// WriteYAML menulis konfigurasi efektif dalam format file konfigurasi sehingga hasilnya dapat
+// dipakai kembali sebagai file. Jika redact bernilai true, secret yang terisi disamarkan.
+func (c *Config) WriteYAML(w io.Writer, redact bool) error {
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	v := reflect.ValueOf(c).Elem()
+	for _, s := range settings {
+		field := v.Field(s.index)
+		value := field.Interface()
+		if d, ok := value.(time.Duration); ok {
+			value = d.String()
+		}
+		if redact && s.secret && !field.IsZero() {
+			value = "[REDACTED]"
+		}
+
+		var node yaml.Node
+		if err := node.Encode(value); err != nil {
+			return fmt.Errorf("could not encode %s: %w", s.key(), err)
+		}
+		doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: s.key()}, &node)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
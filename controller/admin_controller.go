@@ -0,0 +1,176 @@
+package controller
+
+import (
+	"auth-service/model"
+	"auth-service/service"
+	"auth-service/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+type AdminController struct {
+	adminService   *service.AdminService
+	webhookService *service.WebhookService
+	validate       *validator.Validate
+}
+
+func NewAdminController(svc *service.AdminService, webhookService *service.WebhookService, validate *validator.Validate) *AdminController {
+	return &AdminController{
+		adminService:   svc,
+		webhookService: webhookService,
+		validate:       validate,
+	}
+}
+
+func (ac *AdminController) ListUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	page, _ := strconv.Atoi(query.Get("page"))
+	pageSize, _ := strconv.Atoi(query.Get("page_size"))
+
+	result, err := ac.adminService.ListUsers(r.Context(), model.ListUsersInput{
+		Query:    query.Get("q"),
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, result)
+}
+
+func (ac *AdminController) GetUser(w http.ResponseWriter, r *http.Request) {
+	user, err := ac.adminService.GetUser(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, user)
+}
+
+func (ac *AdminController) VerifyUser(w http.ResponseWriter, r *http.Request) {
+	user, err := ac.adminService.VerifyUser(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, user)
+}
+
+func (ac *AdminController) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	var input model.UpdateUserStatusInput
+	if err := utils.DecodeAndValidate(r, &input, ac.validate); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := ac.adminService.SetStatus(r.Context(), chi.URLParam(r, "id"), input)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, user)
+}
+
+func (ac *AdminController) DisableUser(w http.ResponseWriter, r *http.Request) {
+	user, err := ac.adminService.SetStatus(r.Context(), chi.URLParam(r, "id"), model.UpdateUserStatusInput{
+		Status: model.StatusDisabled,
+	})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, user)
+}
+
+func (ac *AdminController) EnableUser(w http.ResponseWriter, r *http.Request) {
+	user, err := ac.adminService.SetStatus(r.Context(), chi.URLParam(r, "id"), model.UpdateUserStatusInput{
+		Status: model.StatusActive,
+	})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, user)
+}
+
+func (ac *AdminController) ForcePasswordReset(w http.ResponseWriter, r *http.Request) {
+	if err := ac.adminService.ForcePasswordReset(r.Context(), chi.URLParam(r, "id")); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]string{"message": "Sessions revoked and password reset email sent."})
+}
+
+func (ac *AdminController) RevokeSessions(w http.ResponseWriter, r *http.Request) {
+	revoked, err := ac.adminService.RevokeSessions(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "All sessions have been revoked.",
+		"revoked": revoked,
+	})
+}
+
+func (ac *AdminController) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	if err := ac.adminService.DeleteUser(r.Context(), chi.URLParam(r, "id")); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]string{"message": "User has been deleted."})
+}
+
+func (ac *AdminController) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	page, _ := strconv.Atoi(query.Get("page"))
+	pageSize, _ := strconv.Atoi(query.Get("page_size"))
+
+	result, err := ac.webhookService.ListDeliveries(r.Context(), model.WebhookDeliveryFilter{
+		EventID:  query.Get("event_id"),
+		Failed:   query.Get("failed") == "true",
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, result)
+}
+
+func (ac *AdminController) Impersonate(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := r.Context().Value(model.ContextKey("userID")).(string)
+	if !ok {
+		utils.WriteError(w, http.StatusInternalServerError, "Failed to get user ID from context")
+		return
+	}
+
+	var input model.ImpersonateInput
+	if err := utils.DecodeAndValidate(r, &input, ac.validate); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	token, err := ac.adminService.Impersonate(r.Context(), actorID, input)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, token)
+}
@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"auth-service/model"
+	"auth-service/service"
+	"auth-service/utils"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type AuditController struct {
+	auditService *service.AuditService
+}
+
+func NewAuditController(svc *service.AuditService) *AuditController {
+	return &AuditController{auditService: svc}
+}
+
+func (ac *AuditController) List(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	page, _ := strconv.Atoi(query.Get("page"))
+	pageSize, _ := strconv.Atoi(query.Get("page_size"))
+
+	from, err := parseTimeParam(query.Get("from"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid 'from' parameter, expected RFC3339 timestamp")
+		return
+	}
+	to, err := parseTimeParam(query.Get("to"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid 'to' parameter, expected RFC3339 timestamp")
+		return
+	}
+
+	filter := model.AuditFilter{
+		Event:     query.Get("event"),
+		ActorID:   query.Get("actor_id"),
+		SubjectID: query.Get("subject_id"),
+		From:      from,
+		To:        to,
+		Page:      page,
+		PageSize:  pageSize,
+	}
+
+	result, err := ac.auditService.List(r.Context(), filter)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, result)
+}
+
+func (ac *AuditController) Verify(w http.ResponseWriter, r *http.Request) {
+	result, err := ac.auditService.Verify(r.Context(), nil)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, result)
+}
+
+// parseTimeParam membaca query parameter RFC3339 opsional.
+func parseTimeParam(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
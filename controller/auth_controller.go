@@ -4,8 +4,8 @@ import (
 	"auth-service/model"
 	"auth-service/service"
 	"auth-service/utils"
-	"errors"
 	"net/http"
+	"net/url"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -13,12 +13,16 @@ import (
 type AuthController struct {
 	authService *service.AuthService
 	validate    *validator.Validate
+	// verifyRedirectURL adalah halaman frontend yang menerima token dari tautan verifikasi email
+	// lalu menukarnya lewat POST /auth/verify-email. Jika kosong, GET hanya memverifikasi akun.
+	verifyRedirectURL string
 }
 
-func NewAuthController(svc *service.AuthService, validate *validator.Validate) *AuthController {
+func NewAuthController(svc *service.AuthService, validate *validator.Validate, verifyRedirectURL string) *AuthController {
 	return &AuthController{
-		authService: svc,
-		validate:    validate,
+		authService:       svc,
+		validate:          validate,
+		verifyRedirectURL: verifyRedirectURL,
 	}
 }
 
@@ -31,17 +35,12 @@ func (ac *AuthController) Register(w http.ResponseWriter, r *http.Request) {
 
 	err := ac.authService.Register(r.Context(), input)
 	if err != nil {
-		var appErr *model.AppError
-		if errors.As(err, &appErr) {
-			utils.WriteError(w, appErr.StatusCode, appErr.Message)
-		} else {
-			utils.WriteError(w, http.StatusInternalServerError, err.Error())
-		}
+		writeServiceError(w, err)
 		return
 	}
 
 	utils.WriteJSON(w, http.StatusCreated, map[string]string{
-		"message": "User registered. Please check your email for the verification OTP.",
+		"message": "User registered. Please check your email or phone to verify your account.",
 	})
 }
 
@@ -54,12 +53,7 @@ func (ac *AuthController) Login(w http.ResponseWriter, r *http.Request) {
 
 	tokens, err := ac.authService.Login(r.Context(), input)
 	if err != nil {
-		var appErr *model.AppError
-		if errors.As(err, &appErr) {
-			utils.WriteError(w, appErr.StatusCode, appErr.Message)
-		} else {
-			utils.WriteError(w, http.StatusInternalServerError, err.Error())
-		}
+		writeServiceError(w, err)
 		return
 	}
 
@@ -75,12 +69,46 @@ func (ac *AuthController) VerifyOTP(w http.ResponseWriter, r *http.Request) {
 
 	tokens, err := ac.authService.VerifyOTP(r.Context(), input.Email, input.OTP)
 	if err != nil {
-		var appErr *model.AppError
-		if errors.As(err, &appErr) {
-			utils.WriteError(w, appErr.StatusCode, appErr.Message)
-		} else {
-			utils.WriteError(w, http.StatusInternalServerError, err.Error())
-		}
+		writeServiceError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, tokens)
+}
+
+// VerifyEmail menangani tautan verifikasi dari email. GET tidak boleh memakai tautan atau
+// menerbitkan sesi karena pemindai tautan di layanan email ikut membukanya. Jika redirect
+// dikonfigurasi, token diteruskan ke frontend lewat fragment URL (#token=...) agar tidak
+// tercatat di log server; frontend lalu memanggil POST /auth/verify-email. Tanpa redirect,
+// akun diverifikasi tanpa menerbitkan token dan pengguna diminta login.
+func (ac *AuthController) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+
+	if ac.verifyRedirectURL != "" {
+		target, _ := url.Parse(ac.verifyRedirectURL)
+		target.Fragment = ""
+		http.Redirect(w, r, target.String()+"#"+url.Values{"token": {token}}.Encode(), http.StatusFound)
+		return
+	}
+
+	if err := ac.authService.ConfirmEmail(r.Context(), token); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, map[string]string{"message": "Email verified. You can now log in."})
+}
+
+// ExchangeEmailToken memakai tautan verifikasi (sekali pakai) dan menerbitkan sesi.
+func (ac *AuthController) ExchangeEmailToken(w http.ResponseWriter, r *http.Request) {
+	var input model.VerifyEmailInput
+	if err := utils.DecodeAndValidate(r, &input, ac.validate); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tokens, err := ac.authService.VerifyEmailLink(r.Context(), input.Token)
+	if err != nil {
+		writeServiceError(w, err)
 		return
 	}
 
@@ -96,12 +124,7 @@ func (ac *AuthController) RefreshToken(w http.ResponseWriter, r *http.Request) {
 
 	tokens, err := ac.authService.RefreshToken(r.Context(), input.Token)
 	if err != nil {
-		var appErr *model.AppError
-		if errors.As(err, &appErr) {
-			utils.WriteError(w, appErr.StatusCode, appErr.Message)
-		} else {
-			utils.WriteError(w, http.StatusInternalServerError, err.Error())
-		}
+		writeServiceError(w, err)
 		return
 	}
 
@@ -117,7 +140,7 @@ func (ac *AuthController) Logout(w http.ResponseWriter, r *http.Request) {
 
 	err := ac.authService.Logout(r.Context(), input.RefreshToken)
 	if err != nil {
-		utils.WriteError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 
@@ -131,14 +154,9 @@ func (ac *AuthController) ForgotPassword(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err := ac.authService.ForgotPassword(r.Context(), input.Email)
+	err := ac.authService.ForgotPassword(r.Context(), input)
 	if err != nil {
-		var appErr *model.AppError
-		if errors.As(err, &appErr) {
-			utils.WriteError(w, appErr.StatusCode, appErr.Message)
-		} else {
-			utils.WriteError(w, http.StatusInternalServerError, err.Error())
-		}
+		writeServiceError(w, err)
 		return
 	}
 
@@ -154,12 +172,7 @@ func (ac *AuthController) ResetPassword(w http.ResponseWriter, r *http.Request)
 
 	err := ac.authService.ResetPassword(r.Context(), input)
 	if err != nil {
-		var appErr *model.AppError
-		if errors.As(err, &appErr) {
-			utils.WriteError(w, appErr.StatusCode, appErr.Message)
-		} else {
-			utils.WriteError(w, http.StatusInternalServerError, err.Error())
-		}
+		writeServiceError(w, err)
 		return
 	}
 
@@ -173,18 +186,76 @@ func (ac *AuthController) ResendOTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := ac.authService.ResendOTP(r.Context(), input.Email)
+	err := ac.authService.ResendOTP(r.Context(), input)
 	if err != nil {
-		var appErr *model.AppError
-		if errors.As(err, &appErr) {
-			utils.WriteError(w, appErr.StatusCode, appErr.Message)
-		} else {
-			utils.WriteError(w, http.StatusInternalServerError, err.Error())
-		}
+		writeServiceError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]string{"message": "A new OTP has been sent."})
+}
+
+func (ac *AuthController) UpdatePhone(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(model.ContextKey("userID")).(string)
+	if !ok {
+		utils.WriteError(w, http.StatusInternalServerError, "Failed to get user ID from context")
+		return
+	}
+
+	var input model.UpdatePhoneInput
+	if err := utils.DecodeAndValidate(r, &input, ac.validate); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := ac.authService.UpdatePhone(r.Context(), userID, input); err != nil {
+		writeServiceError(w, err)
 		return
 	}
 
-	utils.WriteJSON(w, http.StatusOK, map[string]string{"message": "A new OTP has been sent to your email."})
+	utils.WriteJSON(w, http.StatusOK, map[string]string{"message": "An OTP has been sent to your phone."})
+}
+
+func (ac *AuthController) VerifyPhone(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(model.ContextKey("userID")).(string)
+	if !ok {
+		utils.WriteError(w, http.StatusInternalServerError, "Failed to get user ID from context")
+		return
+	}
+
+	var input model.VerifyPhoneInput
+	if err := utils.DecodeAndValidate(r, &input, ac.validate); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := ac.authService.VerifyPhone(r.Context(), userID, input.OTP); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]string{"message": "Phone number verified."})
+}
+
+func (ac *AuthController) UpdateOTPChannel(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(model.ContextKey("userID")).(string)
+	if !ok {
+		utils.WriteError(w, http.StatusInternalServerError, "Failed to get user ID from context")
+		return
+	}
+
+	var input model.UpdateOTPChannelInput
+	if err := utils.DecodeAndValidate(r, &input, ac.validate); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := ac.authService.UpdateOTPChannel(r.Context(), userID, input.Channel); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]string{"message": "OTP channel updated."})
 }
 
 func (ac *AuthController) GetProfile(w http.ResponseWriter, r *http.Request) {
@@ -194,8 +265,13 @@ func (ac *AuthController) GetProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	utils.WriteJSON(w, http.StatusOK, map[string]string{
+	response := map[string]string{
 		"message": "Welcome to your protected profile!",
 		"userID":  userID,
-	})
+	}
+	if actorID, ok := r.Context().Value(model.ContextKey("actorID")).(string); ok {
+		response["impersonatedBy"] = actorID
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
 }
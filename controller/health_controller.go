@@ -0,0 +1,32 @@
+package controller
+
+import (
+	"auth-service/service"
+	"auth-service/utils"
+	"net/http"
+)
+
+type HealthController struct {
+	healthService *service.HealthService
+}
+
+func NewHealthController(svc *service.HealthService) *HealthController {
+	return &HealthController{healthService: svc}
+}
+
+// Liveness hanya menandakan proses masih berjalan; dependency tidak diperiksa agar
+// gangguan Postgres atau Redis tidak membuat pod di-restart.
+func (hc *HealthController) Liveness(w http.ResponseWriter, r *http.Request) {
+	utils.WriteJSON(w, http.StatusOK, map[string]string{"status": service.HealthOK})
+}
+
+// Readiness memeriksa dependency dan mengembalikan 503 jika dependency kritis tidak tersedia.
+func (hc *HealthController) Readiness(w http.ResponseWriter, r *http.Request) {
+	report := hc.healthService.Ready(r.Context())
+
+	status := http.StatusOK
+	if report.Status == service.HealthUnavailable {
+		status = http.StatusServiceUnavailable
+	}
+	utils.WriteJSON(w, status, report)
+}
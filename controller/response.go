@@ -0,0 +1,23 @@
+package controller
+
+import (
+	"auth-service/model"
+	"auth-service/utils"
+	"errors"
+	"net/http"
+)
+
+// writeServiceError memetakan error dari service ke respons HTTP.
+func writeServiceError(w http.ResponseWriter, err error) {
+	var appErr *model.AppError
+	if !errors.As(err, &appErr) {
+		utils.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if appErr.Code != "" {
+		utils.WriteErrorWithCode(w, appErr.StatusCode, appErr.Code, appErr.Message)
+	} else {
+		utils.WriteError(w, appErr.StatusCode, appErr.Message)
+	}
+}
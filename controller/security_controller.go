@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"auth-service/model"
+	"auth-service/service"
+	"auth-service/utils"
+	"net/http"
+	"strconv"
+)
+
+type SecurityController struct {
+	auditService *service.AuditService
+}
+
+func NewSecurityController(svc *service.AuditService) *SecurityController {
+	return &SecurityController{auditService: svc}
+}
+
+func (sc *SecurityController) Activity(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(model.ContextKey("userID")).(string)
+	if !ok {
+		utils.WriteError(w, http.StatusInternalServerError, "Failed to get user ID from context")
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	activity, err := sc.auditService.UserActivity(r.Context(), userID, limit)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"activity": activity})
+}
@@ -0,0 +1,117 @@
+// Package logger menyiapkan slog sebagai logger aplikasi. Setiap record otomatis diberi
+// atribut request_id, user_id, trace_id dan route dari context request jika tersedia.
+package logger
+
+import (
+	"auth-service/model"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// logLevel dipakai bersama oleh semua logger dari New agar dapat diubah saat konfigurasi dimuat ulang.
+var logLevel slog.LevelVar
+
+// New membuat logger dengan level ("debug", "info", "warn", "error") dan format ("json" atau "text").
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	if err := SetLevel(level); err != nil {
+		return nil, err
+	}
+
+	opts := &slog.HandlerOptions{Level: &logLevel}
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "", "json":
+		handler = slog.NewJSONHandler(w, opts)
+	case "text":
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("invalid log format %q", format)
+	}
+	return slog.New(&contextHandler{Handler: handler}), nil
+}
+
+// SetLevel mengubah level minimum semua logger yang dibuat oleh New.
+func SetLevel(level string) error {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid log level %q", level)
+	}
+	logLevel.Set(lvl)
+	return nil
+}
+
+// requestFields menyimpan data request yang baru diketahui di middleware yang lebih dalam
+// (misalnya user ID dari JWTMiddleware) agar tetap terlihat oleh access log di luar.
+type requestFields struct {
+	userID string
+}
+
+type fieldsKey struct{}
+
+// WithRequestFields menyiapkan context request agar SetUserID bisa dipakai.
+func WithRequestFields(ctx context.Context) context.Context {
+	return context.WithValue(ctx, fieldsKey{}, &requestFields{})
+}
+
+// SetUserID mencatat user yang sedang login untuk semua log pada request ini.
+func SetUserID(ctx context.Context, userID string) {
+	if fields, ok := ctx.Value(fieldsKey{}).(*requestFields); ok {
+		fields.userID = userID
+	}
+}
+
+// contextHandler menambahkan atribut dari context ke setiap record.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if ctx != nil {
+		if requestID := middleware.GetReqID(ctx); requestID != "" {
+			record.AddAttrs(slog.String("request_id", requestID))
+		}
+		if userID := userIDFromContext(ctx); userID != "" {
+			record.AddAttrs(slog.String("user_id", userID))
+		}
+		if actorID, ok := ctx.Value(model.ContextKey("actorID")).(string); ok && actorID != "" {
+			record.AddAttrs(slog.String("actor_id", actorID))
+		}
+		if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.IsValid() {
+			record.AddAttrs(
+				slog.String("trace_id", spanCtx.TraceID().String()),
+				slog.String("span_id", spanCtx.SpanID().String()),
+			)
+		}
+		if rctx := chi.RouteContext(ctx); rctx != nil {
+			if route := rctx.RoutePattern(); route != "" {
+				record.AddAttrs(slog.String("route", route))
+			}
+		}
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+func userIDFromContext(ctx context.Context) string {
+	if userID, ok := ctx.Value(model.ContextKey("userID")).(string); ok && userID != "" {
+		return userID
+	}
+	if fields, ok := ctx.Value(fieldsKey{}).(*requestFields); ok {
+		return fields.userID
+	}
+	return ""
+}
@@ -0,0 +1,153 @@
+package mailer
+
+import (
+	"auth-service/config"
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-msgauth/dkim"
+)
+
+// dkimHeaderKeys adalah header yang ikut ditandatangani DKIM.
+var dkimHeaderKeys = []string{"From", "To", "Subject", "Date", "Message-ID", "MIME-Version", "Content-Type"}
+
+// Composer menyusun email mentah sesuai RFC 5322 (header lengkap dan baris CRLF)
+// dan menandatanganinya dengan DKIM jika private key dikonfigurasi.
+type Composer struct {
+	from   mail.Address
+	domain string
+	dkim   *dkim.SignOptions
+}
+
+func NewComposer(cfg *config.Config) (*Composer, error) {
+	from := mail.Address{Name: cfg.MailFromName, Address: cfg.AppEmail}
+	domain := cfg.AppEmail[strings.LastIndex(cfg.AppEmail, "@")+1:]
+
+	c := &Composer{from: from, domain: domain}
+	if cfg.DKIMPrivateKeyFile == "" {
+		return c, nil
+	}
+
+	signer, err := loadDKIMKey(cfg.DKIMPrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	c.dkim = &dkim.SignOptions{
+		Domain:     cfg.DKIMDomain,
+		Selector:   cfg.DKIMSelector,
+		Signer:     signer,
+		HeaderKeys: dkimHeaderKeys,
+		// Relaxed lebih tahan terhadap perubahan spasi oleh relay di tengah jalan.
+		HeaderCanonicalization: dkim.CanonicalizationRelaxed,
+		BodyCanonicalization:   dkim.CanonicalizationRelaxed,
+	}
+	return c, nil
+}
+
+// Sender mengembalikan alamat envelope sender (MAIL FROM).
+func (c *Composer) Sender() string {
+	return c.from.Address
+}
+
+// Compose menyusun email multipart/alternative berisi bagian teks dan HTML.
+func (c *Composer) Compose(msg Message) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if err := writePart(mw, "text/plain", msg.TextBody); err != nil {
+		return nil, err
+	}
+	if err := writePart(mw, "text/html", msg.HTMLBody); err != nil {
+		return nil, err
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	to := mail.Address{Address: msg.To}
+	var b bytes.Buffer
+	writeHeader(&b, "From", c.from.String())
+	writeHeader(&b, "To", to.String())
+	writeHeader(&b, "Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	writeHeader(&b, "Date", time.Now().Format(time.RFC1123Z))
+	writeHeader(&b, "Message-ID", c.messageID())
+	writeHeader(&b, "MIME-Version", "1.0")
+	writeHeader(&b, "Content-Type", mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": mw.Boundary()}))
+	b.WriteString("\r\n")
+	b.Write(body.Bytes())
+
+	if c.dkim == nil {
+		return b.Bytes(), nil
+	}
+
+	var signed bytes.Buffer
+	if err := dkim.Sign(&signed, &b, c.dkim); err != nil {
+		return nil, fmt.Errorf("could not sign message with DKIM: %w", err)
+	}
+	return signed.Bytes(), nil
+}
+
+func (c *Composer) messageID() string {
+	random := make([]byte, 16)
+	rand.Read(random)
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(random), c.domain)
+}
+
+func writeHeader(b *bytes.Buffer, key, value string) {
+	b.WriteString(key + ": " + value + "\r\n")
+}
+
+func writePart(mw *multipart.Writer, contentType, content string) error {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", mime.FormatMediaType(contentType, map[string]string{"charset": "UTF-8"}))
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+	part, err := mw.CreatePart(header)
+	if err != nil {
+		return err
+	}
+
+	// quotedprintable.Writer juga mengubah akhir baris menjadi CRLF.
+	qp := quotedprintable.NewWriter(part)
+	if _, err := qp.Write([]byte(content)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// loadDKIMKey membaca private key RSA atau Ed25519 dalam format PEM (PKCS#1 atau PKCS#8).
+func loadDKIMKey(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read DKIM private key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("DKIM private key is not valid PEM")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse DKIM private key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported DKIM private key type")
+	}
+	return signer, nil
+}
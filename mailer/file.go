@@ -0,0 +1,36 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// FileMailer menulis setiap email sebagai file .eml di sebuah direktori, untuk pengembangan lokal dan pengujian.
+type FileMailer struct {
+	dir      string
+	composer *Composer
+}
+
+func NewFileMailer(dir string, composer *Composer) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("could not create mail directory: %w", err)
+	}
+	return &FileMailer{dir: dir, composer: composer}, nil
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	name := fmt.Sprintf("%s_%s.eml",
+		time.Now().UTC().Format("20060102T150405.000000000"),
+		unsafeFileChars.ReplaceAllString(msg.To, "_"))
+	raw, err := m.composer.Compose(msg)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(m.dir, name), raw, 0o644)
+}
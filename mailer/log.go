@@ -0,0 +1,31 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// LogMailer menulis email ke writer (biasanya stdout) alih-alih mengirimkannya.
+type LogMailer struct {
+	mu       sync.Mutex
+	out      io.Writer
+	composer *Composer
+}
+
+func NewLogMailer(out io.Writer, composer *Composer) *LogMailer {
+	return &LogMailer{out: out, composer: composer}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	raw, err := m.composer.Compose(msg)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, err = fmt.Fprintf(m.out, "----- email -----\n%s\n----- end email -----\n", raw)
+	return err
+}
@@ -0,0 +1,108 @@
+// Package mailer menyediakan abstraksi pengiriman email beserta beberapa backend:
+// SMTP untuk produksi, file .eml untuk pengembangan lokal, dan stdout untuk debugging.
+package mailer
+
+import (
+	"auth-service/config"
+	"auth-service/metrics"
+	"auth-service/tracing"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Driver mailer yang didukung.
+const (
+	DriverSMTP = "smtp"
+	DriverFile = "file"
+	DriverLog  = "log"
+)
+
+// Message adalah email yang akan dikirim.
+type Message struct {
+	To       string
+	Subject  string
+	TextBody string
+	HTMLBody string
+}
+
+// Mailer mengirim email.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Pinger diimplementasikan oleh backend yang bisa diperiksa konektivitasnya (misalnya SMTP).
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// New membuat Mailer sesuai cfg.MailDriver.
+func New(cfg *config.Config) (Mailer, error) {
+	composer, err := NewComposer(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var m Mailer
+	driver := cfg.MailDriver
+	switch driver {
+	case DriverSMTP, "":
+		driver = DriverSMTP
+		m, err = NewSMTPMailer(cfg, composer)
+	case DriverFile:
+		m, err = NewFileMailer(cfg.MailFileDir, composer)
+	case DriverLog:
+		m = NewLogMailer(os.Stdout, composer)
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.MailDriver)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedMailer{Mailer: m, driver: driver}, nil
+}
+
+// instrumentedMailer mencatat latensi dan kegagalan backend ke metrik Prometheus dan
+// membuat span tracing untuk setiap pengiriman.
+type instrumentedMailer struct {
+	Mailer
+	driver string
+}
+
+func (m *instrumentedMailer) Send(ctx context.Context, msg Message) error {
+	ctx, span := tracing.Tracer().Start(ctx, "mailer.send "+m.driver, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("mail.driver", m.driver)))
+	defer span.End()
+
+	start := time.Now()
+	err := m.Mailer.Send(ctx, msg)
+	metrics.EmailSendDuration.WithLabelValues(m.driver).Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.EmailSendFailures.WithLabelValues(m.driver).Inc()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+// Close menutup koneksi backend (misalnya pool SMTP) jika backend menyimpannya.
+func (m *instrumentedMailer) Close() error {
+	if c, ok := m.Mailer.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Ping meneruskan pemeriksaan konektivitas ke backend jika backend mendukungnya.
+func (m *instrumentedMailer) Ping(ctx context.Context) error {
+	if p, ok := m.Mailer.(Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package mailer
+
+import (
+	"auth-service/repository"
+	"auth-service/tracing"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	queuePollTimeout       = 5 * time.Second
+	queueRetryInterval     = time.Second
+	queueBaseBackoff       = 5 * time.Second
+	queueMaxBackoff        = 30 * time.Minute
+	queueHeartbeatInterval = 10 * time.Second
+	queueHeartbeatTTL      = 30 * time.Second
+	queueRecoverInterval   = 30 * time.Second
+)
+
+// queueJob adalah email yang menunggu dikirim beserta riwayat percobaannya.
+type queueJob struct {
+	ID         string    `json:"id"`
+	Message    Message   `json:"message"`
+	Attempts   int       `json:"attempts"`
+	LastError  string    `json:"last_error,omitempty"`
+	EnqueuedAt time.Time `json:"enqueued_at"`
+	// TraceContext membawa trace context W3C dari request yang mengantrekan email
+	// agar pengiriman oleh worker tercatat di trace yang sama.
+	TraceContext map[string]string `json:"trace_context,omitempty"`
+}
+
+// QueueMailer memasukkan email ke antrean Redis dan mengirimkannya lewat backend Mailer dari worker pool.
+// Send langsung kembali setelah job tersimpan sehingga request tidak menunggu server SMTP.
+// Pengiriman bersifat at-least-once: job yang diambil worker dari instance yang mati di tengah
+// pengiriman dikembalikan ke antrean oleh instance lain.
+type QueueMailer struct {
+	repo        *repository.MailQueueRepo
+	backend     Mailer
+	workers     int
+	maxAttempts int
+	instance    string
+}
+
+func NewQueueMailer(repo *repository.MailQueueRepo, backend Mailer, workers, maxAttempts int) *QueueMailer {
+	return &QueueMailer{
+		repo:        repo,
+		backend:     backend,
+		workers:     workers,
+		maxAttempts: maxAttempts,
+		instance:    uuid.New().String(),
+	}
+}
+
+func (q *QueueMailer) Send(ctx context.Context, msg Message) error {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+
+	job, err := json.Marshal(queueJob{
+		ID:           uuid.New().String(),
+		Message:      msg,
+		EnqueuedAt:   time.Now().UTC(),
+		TraceContext: carrier,
+	})
+	if err != nil {
+		return fmt.Errorf("could not encode mail job: %w", err)
+	}
+	if err := q.repo.Enqueue(ctx, job); err != nil {
+		return fmt.Errorf("could not enqueue mail: %w", err)
+	}
+	return nil
+}
+
+// Run menjalankan worker pool, heartbeat dan pemindah job retry hingga ctx dibatalkan,
+// lalu menunggu job yang sedang dikirim selesai.
+func (q *QueueMailer) Run(ctx context.Context) {
+	// Heartbeat harus ada sebelum worker mengambil job agar list processing-nya tidak
+	// dianggap milik instance yang mati.
+	if err := q.repo.Heartbeat(ctx, q.instance, queueHeartbeatTTL); err != nil {
+		slog.ErrorContext(ctx, "failed to register mail queue instance", "error", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < q.workers; i++ {
+		wg.Add(1)
+		worker := repository.MailWorker{Instance: q.instance, ID: i}
+		go func() {
+			defer wg.Done()
+			q.work(ctx, worker)
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		q.maintain(ctx)
+	}()
+
+	wg.Wait()
+	if err := q.repo.StopHeartbeat(context.Background(), q.instance); err != nil {
+		slog.Error("failed to remove mail queue heartbeat", "error", err)
+	}
+}
+
+func (q *QueueMailer) work(ctx context.Context, worker repository.MailWorker) {
+	for ctx.Err() == nil {
+		raw, err := q.repo.Dequeue(ctx, worker, queuePollTimeout)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.ErrorContext(ctx, "failed to read mail queue", "error", err)
+				time.Sleep(queueRetryInterval)
+			}
+			continue
+		}
+		if raw == nil {
+			continue
+		}
+
+		var job queueJob
+		if err := json.Unmarshal(raw, &job); err != nil {
+			slog.ErrorContext(ctx, "dropping malformed mail job to dead-letter", "error", err)
+			if err := q.repo.DeadLetter(context.Background(), worker, raw, raw); err != nil {
+				slog.ErrorContext(ctx, "failed to dead-letter mail job", "error", err)
+			}
+			continue
+		}
+		// Job yang sudah diambil tetap diselesaikan walaupun ctx dibatalkan.
+		q.process(context.Background(), worker, raw, &job)
+	}
+}
+
+// process mengirim job lalu melepasnya dari list processing: ack jika berhasil, dijadwalkan
+// ulang atau dipindah ke dead-letter jika gagal.
+func (q *QueueMailer) process(ctx context.Context, worker repository.MailWorker, raw []byte, job *queueJob) {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(job.TraceContext))
+	ctx, span := tracing.Tracer().Start(ctx, "mailer.queue.process", trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("mail.job_id", job.ID),
+			attribute.Int("mail.attempt", job.Attempts+1),
+		))
+	defer span.End()
+
+	err := q.backend.Send(ctx, job.Message)
+	if err == nil {
+		if err := q.repo.Ack(ctx, worker, raw); err != nil {
+			slog.ErrorContext(ctx, "failed to ack mail job", "job_id", job.ID, "error", err)
+		}
+		return
+	}
+	span.SetStatus(codes.Error, err.Error())
+
+	job.Attempts++
+	job.LastError = err.Error()
+
+	if job.Attempts >= q.maxAttempts {
+		slog.ErrorContext(ctx, "mail job moved to dead-letter", "job_id", job.ID, "attempts", job.Attempts, "error", err)
+		// Isi email (OTP, token reset) tidak disimpan di dead-letter; header dan riwayat
+		// percobaan cukup untuk investigasi.
+		job.Message.TextBody = ""
+		job.Message.HTMLBody = ""
+		dead, _ := json.Marshal(job)
+		if err := q.repo.DeadLetter(ctx, worker, raw, dead); err != nil {
+			slog.ErrorContext(ctx, "failed to dead-letter mail job", "job_id", job.ID, "error", err)
+		}
+		return
+	}
+
+	backoff := queueMaxBackoff
+	if job.Attempts < 16 {
+		backoff = queueBaseBackoff << (job.Attempts - 1)
+	}
+	if backoff > queueMaxBackoff {
+		backoff = queueMaxBackoff
+	}
+	slog.WarnContext(ctx, "mail job failed, retrying", "job_id", job.ID, "attempts", job.Attempts, "backoff", backoff, "error", err)
+	updated, _ := json.Marshal(job)
+	if err := q.repo.ScheduleRetry(ctx, worker, raw, updated, time.Now().Add(backoff)); err != nil {
+		slog.ErrorContext(ctx, "failed to schedule mail job retry", "job_id", job.ID, "error", err)
+	}
+}
+
+// maintain memperbarui heartbeat instance, memindahkan job retry yang jatuh tempo, dan
+// memulihkan job milik instance yang mati.
+func (q *QueueMailer) maintain(ctx context.Context) {
+	retry := time.NewTicker(queueRetryInterval)
+	defer retry.Stop()
+	heartbeat := time.NewTicker(queueHeartbeatInterval)
+	defer heartbeat.Stop()
+	recovery := time.NewTicker(queueRecoverInterval)
+	defer recovery.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-retry.C:
+			if _, err := q.repo.PromoteDue(ctx, now); err != nil && ctx.Err() == nil {
+				slog.ErrorContext(ctx, "failed to promote mail retries", "error", err)
+			}
+		case <-heartbeat.C:
+			if err := q.repo.Heartbeat(ctx, q.instance, queueHeartbeatTTL); err != nil && ctx.Err() == nil {
+				slog.ErrorContext(ctx, "failed to refresh mail queue heartbeat", "error", err)
+			}
+		case <-recovery.C:
+			recovered, err := q.repo.Recover(ctx)
+			if err != nil && ctx.Err() == nil {
+				slog.ErrorContext(ctx, "failed to recover abandoned mail jobs", "error", err)
+			} else if recovered > 0 {
+				slog.WarnContext(ctx, "requeued mail jobs abandoned by a stopped instance", "jobs", recovered)
+			}
+		}
+	}
+}
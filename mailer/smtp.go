@@ -0,0 +1,247 @@
+package mailer
+
+import (
+	"auth-service/config"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"sync"
+	"time"
+)
+
+// Mode TLS untuk koneksi SMTP.
+const (
+	TLSModeImplicit = "implicit" // TLS sejak awal koneksi, biasanya port 465
+	TLSModeSTARTTLS = "starttls" // upgrade ke TLS dengan perintah STARTTLS, biasanya port 587
+	TLSModeNone     = "none"     // tanpa TLS, hanya untuk relay internal
+)
+
+// Kebijakan STARTTLS ketika TLSMode adalah starttls.
+const (
+	STARTTLSRequired      = "required"
+	STARTTLSOpportunistic = "opportunistic"
+)
+
+// SMTPMailer mengirim email melalui server SMTP dan menyimpan koneksi yang menganggur
+// untuk dipakai ulang saat terjadi lonjakan pengiriman.
+type SMTPMailer struct {
+	addr           string
+	host           string
+	user           string
+	password       string
+	composer       *Composer
+	tlsMode        string
+	starttlsPolicy string
+	tlsConfig      *tls.Config
+	timeout        time.Duration
+	idleTimeout    time.Duration
+
+	mu     sync.Mutex
+	idle   []*smtpConn
+	max    int
+	closed bool
+}
+
+type smtpConn struct {
+	conn     net.Conn
+	client   *smtp.Client
+	lastUsed time.Time
+}
+
+func NewSMTPMailer(cfg *config.Config, composer *Composer) (*SMTPMailer, error) {
+	tlsConfig := &tls.Config{
+		ServerName: cfg.SmtpHost,
+		MinVersion: tls.VersionTLS12,
+	}
+	if cfg.SmtpCAFile != "" {
+		pem, err := os.ReadFile(cfg.SmtpCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read SMTP CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("SMTP CA file contains no valid certificates")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return &SMTPMailer{
+		addr:           net.JoinHostPort(cfg.SmtpHost, cfg.SmtpPort),
+		host:           cfg.SmtpHost,
+		user:           cfg.SmtpUser,
+		password:       cfg.SmtpPassword,
+		composer:       composer,
+		tlsMode:        cfg.SmtpTLSMode,
+		starttlsPolicy: cfg.SmtpSTARTTLSPolicy,
+		tlsConfig:      tlsConfig,
+		timeout:        cfg.SmtpTimeout,
+		idleTimeout:    cfg.SmtpPoolIdleTimeout,
+		max:            cfg.SmtpPoolSize,
+	}, nil
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	raw, err := m.composer.Compose(msg)
+	if err != nil {
+		return err
+	}
+
+	c, err := m.acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	reusable, err := m.deliver(c, msg.To, raw)
+	if err != nil && !reusable {
+		c.conn.Close()
+		return err
+	}
+	m.release(c)
+	return err
+}
+
+// Ping memastikan server SMTP dapat dihubungi, termasuk negosiasi TLS dan autentikasi.
+// Koneksi yang berhasil dikembalikan ke pool sehingga bisa dipakai oleh Send berikutnya.
+func (m *SMTPMailer) Ping(ctx context.Context) error {
+	c, err := m.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	m.release(c)
+	return nil
+}
+
+// Close menutup semua koneksi yang menganggur.
+func (m *SMTPMailer) Close() error {
+	m.mu.Lock()
+	idle := m.idle
+	m.idle = nil
+	m.closed = true
+	m.mu.Unlock()
+
+	for _, c := range idle {
+		c.client.Quit()
+	}
+	return nil
+}
+
+// deliver mengirim satu pesan. reusable bernilai true jika koneksi masih dalam keadaan bersih
+// dan boleh dikembalikan ke pool walaupun pengiriman gagal.
+func (m *SMTPMailer) deliver(c *smtpConn, to string, raw []byte) (reusable bool, err error) {
+	c.conn.SetDeadline(time.Now().Add(m.timeout))
+
+	if err := c.client.Mail(m.composer.Sender()); err != nil {
+		return false, fmt.Errorf("smtp MAIL FROM: %w", err)
+	}
+	if err := c.client.Rcpt(to); err != nil {
+		// Penerima yang ditolak tidak merusak sesi; setelah RSET berhasil koneksi bisa
+		// dipakai lagi untuk pesan berikutnya.
+		return c.client.Reset() == nil, fmt.Errorf("smtp RCPT TO: %w", err)
+	}
+	w, err := c.client.Data()
+	if err != nil {
+		return false, fmt.Errorf("smtp DATA: %w", err)
+	}
+	if _, err := w.Write(raw); err != nil {
+		return false, fmt.Errorf("smtp write: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return false, fmt.Errorf("smtp DATA end: %w", err)
+	}
+	return true, nil
+}
+
+// acquire mengambil koneksi menganggur yang masih sehat, atau membuka koneksi baru.
+func (m *SMTPMailer) acquire(ctx context.Context) (*smtpConn, error) {
+	for {
+		m.mu.Lock()
+		if len(m.idle) == 0 {
+			m.mu.Unlock()
+			return m.dial(ctx)
+		}
+		c := m.idle[len(m.idle)-1]
+		m.idle = m.idle[:len(m.idle)-1]
+		m.mu.Unlock()
+
+		if time.Since(c.lastUsed) > m.idleTimeout {
+			c.conn.Close()
+			continue
+		}
+		c.conn.SetDeadline(time.Now().Add(m.timeout))
+		if err := c.client.Noop(); err != nil {
+			c.conn.Close()
+			continue
+		}
+		return c, nil
+	}
+}
+
+func (m *SMTPMailer) release(c *smtpConn) {
+	c.lastUsed = time.Now()
+
+	m.mu.Lock()
+	if !m.closed && len(m.idle) < m.max {
+		m.idle = append(m.idle, c)
+		m.mu.Unlock()
+		return
+	}
+	m.mu.Unlock()
+	c.client.Quit()
+}
+
+func (m *SMTPMailer) dial(ctx context.Context) (*smtpConn, error) {
+	dialer := &net.Dialer{Timeout: m.timeout}
+	var conn net.Conn
+	var err error
+	if m.tlsMode == TLSModeImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: m.tlsConfig}).DialContext(ctx, "tcp", m.addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", m.addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to SMTP server: %w", err)
+	}
+	conn.SetDeadline(time.Now().Add(m.timeout))
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("smtp greeting: %w", err)
+	}
+
+	if err := m.negotiate(client); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return &smtpConn{conn: conn, client: client}, nil
+}
+
+// negotiate menjalankan STARTTLS sesuai kebijakan lalu autentikasi jika kredensial dikonfigurasi.
+func (m *SMTPMailer) negotiate(client *smtp.Client) error {
+	if m.tlsMode == TLSModeSTARTTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(m.tlsConfig); err != nil {
+				return fmt.Errorf("smtp STARTTLS: %w", err)
+			}
+		} else if m.starttlsPolicy == STARTTLSRequired {
+			return errors.New("smtp server does not support STARTTLS")
+		}
+	}
+
+	if m.user == "" {
+		return nil
+	}
+	if ok, _ := client.Extension("AUTH"); !ok {
+		return errors.New("smtp server does not support AUTH")
+	}
+	// PlainAuth menolak mengirim kredensial lewat koneksi tanpa TLS kecuali ke localhost.
+	if err := client.Auth(smtp.PlainAuth("", m.user, m.password, m.host)); err != nil {
+		return fmt.Errorf("smtp AUTH: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"strings"
+	"sync/atomic"
+	texttemplate "text/template"
+	"time"
+)
+
+// Nama template email.
+const (
+	TemplateOTP           = "otp"
+	TemplateResetPassword = "reset_password"
+	TemplateVerifyEmail   = "verify_email"
+)
+
+var templateNames = []string{TemplateOTP, TemplateResetPassword, TemplateVerifyEmail}
+
+//go:embed templates
+var defaultTemplates embed.FS
+
+// TemplateData adalah data yang tersedia di semua template email.
+type TemplateData struct {
+	AppName         string
+	Locale          string
+	Year            int
+	ValidityMinutes int
+	OTP             string
+	Token           string
+	// Link dipakai oleh email verifikasi dan reset password berbasis tautan.
+	Link              string
+	LinkValidityHours int
+}
+
+// compiledTemplate berisi tiga bagian sebuah email untuk satu locale.
+type compiledTemplate struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+// Renderer menyusun Message dari template per locale. Setiap subdirektori di direktori
+// template adalah satu locale dan harus memiliki <locale>/<nama>.subject.txt,
+// <locale>/<nama>.txt dan <locale>/<nama>.html; file HTML memakai layout.html di root
+// direktori. Locale baru cukup ditambahkan sebagai subdirektori baru.
+type Renderer struct {
+	set atomic.Pointer[templateSet]
+}
+
+// templateSet adalah hasil satu kali pemuatan template; diganti utuh saat Reload.
+type templateSet struct {
+	appName       string
+	defaultLocale string
+	locales       []string
+	templates     map[string]map[string]*compiledTemplate
+}
+
+// NewRenderer memuat template dari dir, atau dari template bawaan jika dir kosong.
+// Semua template untuk semua locale diparse di awal sehingga kesalahan terdeteksi saat startup.
+func NewRenderer(dir, appName, defaultLocale string) (*Renderer, error) {
+	r := &Renderer{}
+	if err := r.Reload(dir, appName, defaultLocale); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload memuat ulang semua template. Template lama tetap dipakai jika ada yang gagal diparse.
+func (r *Renderer) Reload(dir, appName, defaultLocale string) error {
+	set, err := loadTemplateSet(dir, appName, defaultLocale)
+	if err != nil {
+		return err
+	}
+	r.set.Store(set)
+	return nil
+}
+
+func loadTemplateSet(dir, appName, defaultLocale string) (*templateSet, error) {
+	var fsys fs.FS
+	if dir == "" {
+		sub, err := fs.Sub(defaultTemplates, "templates")
+		if err != nil {
+			return nil, err
+		}
+		fsys = sub
+	} else {
+		fsys = os.DirFS(dir)
+	}
+
+	set := &templateSet{
+		appName:       appName,
+		defaultLocale: defaultLocale,
+		templates:     make(map[string]map[string]*compiledTemplate),
+	}
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("could not read email template directory: %w", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		locale := entry.Name()
+		set.locales = append(set.locales, locale)
+		set.templates[locale] = make(map[string]*compiledTemplate)
+		for _, name := range templateNames {
+			tmpl, err := loadTemplate(fsys, locale, name)
+			if err != nil {
+				return nil, fmt.Errorf("could not load email template %s/%s: %w", locale, name, err)
+			}
+			set.templates[locale][name] = tmpl
+		}
+	}
+
+	if _, ok := set.templates[defaultLocale]; !ok {
+		return nil, fmt.Errorf("default locale %q has no email templates", defaultLocale)
+	}
+	return set, nil
+}
+
+func loadTemplate(fsys fs.FS, locale, name string) (*compiledTemplate, error) {
+	base := locale + "/" + name
+	subject, err := texttemplate.ParseFS(fsys, base+".subject.txt")
+	if err != nil {
+		return nil, err
+	}
+	text, err := texttemplate.ParseFS(fsys, base+".txt")
+	if err != nil {
+		return nil, err
+	}
+	html, err := htmltemplate.ParseFS(fsys, "layout.html", base+".html")
+	if err != nil {
+		return nil, err
+	}
+	return &compiledTemplate{subject: subject, text: text, html: html}, nil
+}
+
+// Locales mengembalikan locale yang memiliki template, diurutkan menurut nama.
+func (r *Renderer) Locales() []string {
+	return append([]string(nil), r.set.Load().locales...)
+}
+
+// HasLocale melaporkan apakah locale memiliki template.
+func (r *Renderer) HasLocale(locale string) bool {
+	_, ok := r.set.Load().templates[locale]
+	return ok
+}
+
+// OTP menyusun email berisi kode OTP.
+func (r *Renderer) OTP(to, locale, otp string, validity time.Duration) (Message, error) {
+	return r.render(to, locale, TemplateOTP, TemplateData{
+		ValidityMinutes: int(validity.Minutes()),
+		OTP:             otp,
+	})
+}
+
+// ResetPassword menyusun email reset password. Jika link tidak kosong, email berisi tautan ke
+// halaman reset di frontend; jika kosong, email berisi token mentah.
+func (r *Renderer) ResetPassword(to, locale, token, link string, validity time.Duration) (Message, error) {
+	return r.render(to, locale, TemplateResetPassword, TemplateData{
+		ValidityMinutes: int(validity.Minutes()),
+		Token:           token,
+		Link:            link,
+	})
+}
+
+// VerifyEmail menyusun email verifikasi akun berisi tautan. Jika otp tidak kosong,
+// kode OTP juga dicantumkan sebagai alternatif.
+func (r *Renderer) VerifyEmail(to, locale, otp string, otpValidity time.Duration, link string, linkValidity time.Duration) (Message, error) {
+	return r.render(to, locale, TemplateVerifyEmail, TemplateData{
+		ValidityMinutes:   int(otpValidity.Minutes()),
+		OTP:               otp,
+		Link:              link,
+		LinkValidityHours: int(linkValidity.Hours()),
+	})
+}
+
+func (r *Renderer) render(to, locale, name string, data TemplateData) (Message, error) {
+	set := r.set.Load()
+	templates, ok := set.templates[locale]
+	if !ok {
+		locale = set.defaultLocale
+		templates = set.templates[locale]
+	}
+	tmpl := templates[name]
+
+	data.AppName = set.appName
+	data.Locale = locale
+	data.Year = time.Now().Year()
+
+	var subject, text, html bytes.Buffer
+	if err := tmpl.subject.Execute(&subject, data); err != nil {
+		return Message{}, fmt.Errorf("could not render email subject: %w", err)
+	}
+	if err := tmpl.text.Execute(&text, data); err != nil {
+		return Message{}, fmt.Errorf("could not render text email: %w", err)
+	}
+	if err := tmpl.html.ExecuteTemplate(&html, "layout", data); err != nil {
+		return Message{}, fmt.Errorf("could not render HTML email: %w", err)
+	}
+
+	return Message{
+		To:       to,
+		Subject:  strings.TrimSpace(subject.String()),
+		TextBody: text.String(),
+		HTMLBody: html.String(),
+	}, nil
+}
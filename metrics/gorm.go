@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const gormStartKey = "metrics:start"
+
+// InstrumentGORM mendaftarkan callback GORM yang mencatat latensi setiap query.
+func InstrumentGORM(db *gorm.DB) error {
+	type processor interface {
+		Register(name string, fn func(*gorm.DB)) error
+	}
+	register := func(operation string, before, after processor) error {
+		if err := before.Register("metrics:before_"+operation, startTimer); err != nil {
+			return err
+		}
+		return after.Register("metrics:after_"+operation, observe(operation))
+	}
+
+	cb := db.Callback()
+	if err := register("create", cb.Create().Before("gorm:create"), cb.Create().After("gorm:create")); err != nil {
+		return err
+	}
+	if err := register("query", cb.Query().Before("gorm:query"), cb.Query().After("gorm:query")); err != nil {
+		return err
+	}
+	if err := register("update", cb.Update().Before("gorm:update"), cb.Update().After("gorm:update")); err != nil {
+		return err
+	}
+	if err := register("delete", cb.Delete().Before("gorm:delete"), cb.Delete().After("gorm:delete")); err != nil {
+		return err
+	}
+	if err := register("row", cb.Row().Before("gorm:row"), cb.Row().After("gorm:row")); err != nil {
+		return err
+	}
+	return register("raw", cb.Raw().Before("gorm:raw"), cb.Raw().After("gorm:raw"))
+}
+
+func startTimer(db *gorm.DB) {
+	db.InstanceSet(gormStartKey, time.Now())
+}
+
+func observe(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(gormStartKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		result := "ok"
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			result = "error"
+		}
+		DBQueryDuration.WithLabelValues(operation, table, result).Observe(time.Since(start).Seconds())
+	}
+}
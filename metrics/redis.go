@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisHook mencatat latensi setiap perintah Redis. Pasang dengan client.AddHook(metrics.RedisHook{}).
+type RedisHook struct{}
+
+func (RedisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		start := time.Now()
+		conn, err := next(ctx, network, addr)
+		RedisCommandDuration.WithLabelValues("dial", status(err)).Observe(time.Since(start).Seconds())
+		return conn, err
+	}
+}
+
+func (RedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		RedisCommandDuration.WithLabelValues(cmd.Name(), redisStatus(err)).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+func (RedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		RedisCommandDuration.WithLabelValues("pipeline", redisStatus(err)).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// redisStatus tidak menganggap redis.Nil (key tidak ada) sebagai error.
+func redisStatus(err error) string {
+	if err == redis.Nil {
+		return "ok"
+	}
+	return status(err)
+}
+
+func status(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
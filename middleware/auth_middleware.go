@@ -1,18 +1,26 @@
 package middleware
 
 import (
+	"auth-service/logger"
+	"auth-service/model"
+	"auth-service/repository"
 	"auth-service/utils"
 	"context"
+	"errors"
 	"net/http"
 	"strings"
 )
 
-type contextKey string
-
-const UserIDKey = contextKey("userID")
+// Kunci context yang diisi oleh JWTMiddleware. Memakai model.ContextKey agar dapat dibaca oleh controller.
+const (
+	UserIDKey  = model.ContextKey("userID")
+	UserKey    = model.ContextKey("user")
+	ActorIDKey = model.ContextKey("actorID")
+)
 
-// JWTMiddleware memvalidasi token JWT dari header Authorization.
-func JWTMiddleware(jwtSecret string) func(http.Handler) http.Handler {
+// JWTMiddleware memvalidasi token JWT dari header Authorization dan memastikan status akun masih aktif.
+// jwtSecrets berisi secret aktif diikuti secret lama yang masih diterima.
+func JWTMiddleware(jwtSecrets []string, userRepo repository.UserStore) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -27,15 +35,92 @@ func JWTMiddleware(jwtSecret string) func(http.Handler) http.Handler {
 				return
 			}
 
-			userID, err := utils.ValidateJWT(tokenStr, jwtSecret)
+			claims, err := utils.ParseJWT(tokenStr, jwtSecrets...)
 			if err != nil {
 				utils.WriteError(w, http.StatusUnauthorized, "invalid token: "+err.Error())
 				return
 			}
+			userID := claims.UserID
+
+			// Status akun dicek pada setiap request agar akun yang diblokir tidak bisa
+			// memakai access token yang masih berlaku.
+			user, err := userRepo.FindByID(r.Context(), userID)
+			if err != nil {
+				writeAppError(w, model.ErrInvalidToken)
+				return
+			}
+			if err := user.CheckStatus(); err != nil {
+				var appErr *model.AppError
+				if errors.As(err, &appErr) {
+					writeAppError(w, appErr)
+				} else {
+					utils.WriteError(w, http.StatusForbidden, err.Error())
+				}
+				return
+			}
 
 			// inject user ID ke dalam context
+			logger.SetUserID(r.Context(), userID)
 			ctx := context.WithValue(r.Context(), UserIDKey, userID)
+			ctx = context.WithValue(ctx, UserKey, user)
+			if claims.ActorID != "" {
+				// Admin yang melakukan impersonasi juga harus masih aktif dan masih superadmin, agar
+				// token impersonasi tidak bisa dipakai setelah akun admin diblokir atau diturunkan.
+				actor, err := userRepo.FindByID(r.Context(), claims.ActorID)
+				if err != nil {
+					writeAppError(w, model.ErrInvalidToken)
+					return
+				}
+				if actor.CheckStatus() != nil || actor.Role != model.RoleSuperAdmin {
+					writeAppError(w, model.ErrInvalidToken)
+					return
+				}
+				ctx = context.WithValue(ctx, ActorIDKey, claims.ActorID)
+			}
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// RequireRole membatasi akses hanya untuk user dengan salah satu role yang diberikan.
+// Harus dipasang setelah JWTMiddleware. Token hasil impersonasi selalu ditolak.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := r.Context().Value(UserKey).(*model.User)
+			if !ok {
+				utils.WriteError(w, http.StatusUnauthorized, "authorization required")
+				return
+			}
+			if _, impersonated := r.Context().Value(ActorIDKey).(string); impersonated {
+				writeAppError(w, model.ErrForbidden)
+				return
+			}
+
+			for _, role := range roles {
+				if user.Role == role {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			writeAppError(w, model.ErrForbidden)
+		})
+	}
+}
+
+// RejectImpersonation menolak token hasil impersonasi. Dipasang pada route yang mengubah
+// kredensial atau faktor autentikasi, agar admin tidak bisa mengambil alih akun yang diimpersonasi.
+func RejectImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, impersonated := r.Context().Value(ActorIDKey).(string); impersonated {
+			writeAppError(w, model.ErrForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeAppError(w http.ResponseWriter, appErr *model.AppError) {
+	utils.WriteErrorWithCode(w, appErr.StatusCode, appErr.Code, appErr.Message)
+}
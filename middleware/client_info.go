@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"auth-service/model"
+	"net"
+	"net/http"
+)
+
+// ClientInfo menyimpan alamat IP dan user agent klien ke dalam context request.
+func ClientInfo(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := model.WithClientInfo(r.Context(), model.ClientInfo{
+			IPAddress: clientIP(r),
+			UserAgent: r.UserAgent(),
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func clientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"auth-service/logger"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// RequestLogger mencatat satu baris access log terstruktur per request dan mengembalikan
+// request ID di header X-Request-Id. Harus dipasang setelah middleware.RequestID.
+func RequestLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := logger.WithRequestFields(r.Context())
+		if requestID := middleware.GetReqID(ctx); requestID != "" {
+			w.Header().Set(middleware.RequestIDHeader, requestID)
+		}
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		start := time.Now()
+
+		defer func() {
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			level := slog.LevelInfo
+			switch {
+			case status >= 500:
+				level = slog.LevelError
+			case status >= 400:
+				level = slog.LevelWarn
+			}
+
+			slog.LogAttrs(ctx, level, "http request",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", status),
+				slog.Int("bytes", ww.BytesWritten()),
+				slog.Duration("duration", time.Since(start)),
+				slog.String("remote_ip", clientIP(r)),
+			)
+		}()
+
+		next.ServeHTTP(ww, r.WithContext(ctx))
+	})
+}
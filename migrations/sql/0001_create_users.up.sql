@@ -0,0 +1,15 @@
+-- Sebelum ada migrasi, tabel users dibuat manual. Semua migrasi awal (0001-0006) memakai
+-- IF NOT EXISTS agar dapat dijalankan di database lama; jika skema lama berbeda, tandai
+-- versi yang sudah sesuai dengan `migrate baseline <versi>`.
+CREATE TABLE IF NOT EXISTS users (
+    id            uuid PRIMARY KEY,
+    email         text        NOT NULL,
+    password_hash text        NOT NULL,
+    role          text        NOT NULL DEFAULT 'user',
+    is_verified   boolean     NOT NULL DEFAULT false,
+    locale        text        NOT NULL DEFAULT '',
+    created_at    timestamptz NOT NULL DEFAULT now(),
+    updated_at    timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
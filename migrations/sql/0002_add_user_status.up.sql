@@ -0,0 +1,7 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS status            text        NOT NULL DEFAULT 'active',
+    ADD COLUMN IF NOT EXISTS status_reason     text        NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS status_changed_at timestamptz,
+    ADD COLUMN IF NOT EXISTS suspended_until   timestamptz;
+
+CREATE INDEX IF NOT EXISTS idx_users_status ON users (status);
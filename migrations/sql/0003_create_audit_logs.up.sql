@@ -0,0 +1,20 @@
+-- Audit log hanya boleh ditambah. Setiap baris menyimpan hash baris sebelumnya (prev_hash)
+-- sehingga perubahan atau penghapusan dapat dideteksi oleh `auditctl verify`.
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id         bigserial PRIMARY KEY,
+    event      text        NOT NULL,
+    actor_id   text        NOT NULL DEFAULT '',
+    subject_id text        NOT NULL DEFAULT '',
+    ip_address text        NOT NULL DEFAULT '',
+    user_agent text        NOT NULL DEFAULT '',
+    details    jsonb       NOT NULL DEFAULT '{}',
+    created_at timestamptz NOT NULL,
+    prev_hash  text        NOT NULL,
+    hash       text        NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_event ON audit_logs (event);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id ON audit_logs (actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_subject_id ON audit_logs (subject_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_logs_hash ON audit_logs (hash);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id          bigserial PRIMARY KEY,
+    event_id    text        NOT NULL,
+    event_type  text        NOT NULL,
+    url         text        NOT NULL,
+    attempt     integer     NOT NULL,
+    status_code integer     NOT NULL DEFAULT 0,
+    error       text        NOT NULL DEFAULT '',
+    duration_ms bigint      NOT NULL DEFAULT 0,
+    succeeded   boolean     NOT NULL DEFAULT false,
+    created_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_event_id ON webhook_deliveries (event_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_created_at ON webhook_deliveries (created_at);
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id           uuid PRIMARY KEY,
+    type         text        NOT NULL,
+    payload      jsonb       NOT NULL DEFAULT '{}',
+    attempts     integer     NOT NULL DEFAULT 0,
+    last_error   text        NOT NULL DEFAULT '',
+    available_at timestamptz NOT NULL,
+    locked_until timestamptz,
+    processed_at timestamptz,
+    failed_at    timestamptz,
+    created_at   timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_type ON outbox_events (type);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_available_at ON outbox_events (available_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_processed_at ON outbox_events (processed_at);
+-- Dipakai oleh OutboxRepo.Claim untuk mencari event yang belum selesai.
+CREATE INDEX IF NOT EXISTS idx_outbox_events_pending ON outbox_events (created_at)
+    WHERE processed_at IS NULL AND failed_at IS NULL;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_users_phone;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS otp_channel,
+    DROP COLUMN IF EXISTS phone_verified,
+    DROP COLUMN IF EXISTS phone;
@@ -0,0 +1,6 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS phone          text    NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS phone_verified boolean NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS otp_channel    text    NOT NULL DEFAULT 'email';
+
+CREATE INDEX IF NOT EXISTS idx_users_phone ON users (phone);
@@ -0,0 +1,16 @@
+-- Audit log hanya boleh ditambah: UPDATE, DELETE dan TRUNCATE ditolak di level database,
+-- termasuk untuk user aplikasi. Rantai HMAC tetap menjadi bukti jika trigger dilepas.
+CREATE FUNCTION audit_logs_append_only() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$;
+
+CREATE TRIGGER audit_logs_no_modify
+    BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only();
+
+CREATE TRIGGER audit_logs_no_truncate
+    BEFORE TRUNCATE ON audit_logs
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_logs_append_only();
@@ -0,0 +1,149 @@
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Jenis event yang dicatat ke audit log.
+const (
+	AuditLoginSucceeded         = "auth.login_succeeded"
+	AuditLoginFailed            = "auth.login_failed"
+	AuditNewDevice              = "auth.new_device"
+	AuditOTPIssued              = "auth.otp_issued"
+	AuditOTPVerified            = "auth.otp_verified"
+	AuditOTPFailed              = "auth.otp_failed"
+	AuditPhoneVerified          = "auth.phone_verified"
+	AuditVerificationLinkSent   = "auth.verification_link_sent"
+	AuditEmailVerified          = "auth.email_verified"
+	AuditPasswordResetRequested = "auth.password_reset_requested"
+	AuditPasswordReset          = "auth.password_reset"
+	AuditLogout                 = "auth.logout"
+	AuditTokenRefreshed         = "auth.token_refreshed"
+	AuditUserCreated            = "admin.user_created"
+	AuditUserVerified           = "admin.user_verified"
+	AuditUserStatusChanged      = "admin.user_status_changed"
+	AuditPasswordResetForced    = "admin.password_reset_forced"
+	AuditPasswordSet            = "admin.password_set"
+	AuditSessionsRevoked        = "admin.sessions_revoked"
+	AuditUserDeleted            = "admin.user_deleted"
+	AuditImpersonationStarted   = "admin.impersonation_started"
+)
+
+// JSONMap menyimpan data tambahan bebas sebagai kolom jsonb.
+type JSONMap map[string]interface{}
+
+func (m JSONMap) Value() (driver.Value, error) {
+	if m == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (m *JSONMap) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*m = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.New("unsupported type for JSONMap")
+	}
+	return json.Unmarshal(data, m)
+}
+
+// AuditLog adalah catatan event keamanan. Tabel ini hanya boleh ditambah, tidak pernah diubah.
+// Setiap entri menyimpan hash entri sebelumnya sehingga perubahan atau penghapusan dapat dideteksi.
+// Hash berupa HMAC dengan kunci yang tidak disimpan di database, sehingga pihak yang hanya
+// memiliki akses tulis ke tabel tidak dapat menghitung ulang rantai setelah mengubah entri.
+type AuditLog struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	Event     string    `gorm:"not null;index" json:"event"`
+	ActorID   string    `gorm:"index" json:"actor_id,omitempty"`
+	SubjectID string    `gorm:"index" json:"subject_id,omitempty"`
+	IPAddress string    `json:"ip_address,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	Details   JSONMap   `gorm:"type:jsonb" json:"details,omitempty"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+	PrevHash  string    `gorm:"not null" json:"prev_hash"`
+	Hash      string    `gorm:"not null;uniqueIndex" json:"hash"`
+}
+
+// ComputeHash menghitung HMAC-SHA256 dari isi entri dan PrevHash dengan kunci key.
+// CreatedAt harus sudah dibulatkan ke mikrodetik (presisi Postgres) sebelum hash dihitung.
+func (l *AuditLog) ComputeHash(key []byte) (string, error) {
+	// Details nil dan kosong sama-sama tersimpan sebagai "{}" di database.
+	details := []byte("{}")
+	if len(l.Details) > 0 {
+		var err error
+		if details, err = json.Marshal(l.Details); err != nil {
+			return "", err
+		}
+	}
+
+	// Setiap field dipisahkan dengan karakter yang tidak mungkin muncul di dalam nilainya.
+	payload := strings.Join([]string{
+		l.PrevHash,
+		l.Event,
+		l.ActorID,
+		l.SubjectID,
+		l.IPAddress,
+		l.UserAgent,
+		string(details),
+		l.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}, "\x1f")
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// AuditAnchor adalah ujung rantai yang pernah dicatat di luar database (misalnya hasil
+// `auditctl verify` yang disimpan operator). Verifikasi gagal jika entri ini hilang atau
+// berubah, sehingga pemotongan ekor rantai juga terdeteksi.
+type AuditAnchor struct {
+	ID   uint64
+	Hash string
+}
+
+// AuditFilter adalah kriteria pencarian audit log.
+type AuditFilter struct {
+	Event     string
+	ActorID   string
+	SubjectID string
+	From      *time.Time
+	To        *time.Time
+	Page      int
+	PageSize  int
+}
+
+// ActivityEntry adalah event keamanan yang ditampilkan ke pemilik akun.
+type ActivityEntry struct {
+	Type      string    `json:"type"`
+	IPAddress string    `json:"ip_address,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ActivityTypes memetakan event audit ke jenis aktivitas yang ditampilkan ke pengguna.
+var ActivityTypes = map[string]string{
+	AuditLoginSucceeded: "login",
+	AuditLoginFailed:    "login_failed",
+	AuditNewDevice:      "new_device",
+	AuditPasswordReset:  "password_changed",
+	AuditPhoneVerified:  "phone_verified",
+}
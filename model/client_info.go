@@ -0,0 +1,22 @@
+package model
+
+import "context"
+
+// ClientInfo berisi informasi klien pembuat request, dipakai untuk audit.
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
+const clientInfoKey = ContextKey("clientInfo")
+
+// WithClientInfo menyimpan ClientInfo ke dalam context.
+func WithClientInfo(ctx context.Context, info ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey, info)
+}
+
+// ClientInfoFromContext mengambil ClientInfo dari context, atau nilai kosong jika tidak ada.
+func ClientInfoFromContext(ctx context.Context) ClientInfo {
+	info, _ := ctx.Value(clientInfoKey).(ClientInfo)
+	return info
+}
@@ -5,6 +5,7 @@ import "fmt"
 // AppError adalah tipe error kustom untuk aplikasi ini.
 type AppError struct {
 	StatusCode int
+	Code       string
 	Message    string
 }
 
@@ -20,12 +21,33 @@ func NewAppError(statusCode int, message string) *AppError {
 	}
 }
 
+// NewAppErrorWithCode membuat AppError dengan kode yang bisa dibaca mesin oleh klien.
+func NewAppErrorWithCode(statusCode int, code, message string) *AppError {
+	return &AppError{
+		StatusCode: statusCode,
+		Code:       code,
+		Message:    message,
+	}
+}
+
 // Pre-defined errors
 var (
-	ErrInvalidCredentials = NewAppError(401, "invalid email or password")
-	ErrAccountNotVerified = NewAppError(403, "account is not verified")
-	ErrUserAlreadyExists  = NewAppError(409, "user with this email already exists")
-	ErrUserNotFound       = NewAppError(404, "user not found")
-	ErrInvalidOTP         = NewAppError(400, "invalid or expired OTP")
-	ErrInvalidToken       = NewAppError(401, "invalid or expired token")
+	ErrInvalidCredentials     = NewAppErrorWithCode(401, "invalid_credentials", "invalid email or password")
+	ErrAccountNotVerified     = NewAppErrorWithCode(403, "account_not_verified", "account is not verified")
+	ErrUserAlreadyExists      = NewAppErrorWithCode(409, "user_already_exists", "user with this email already exists")
+	ErrUserNotFound           = NewAppErrorWithCode(404, "user_not_found", "user not found")
+	ErrInvalidOTP             = NewAppErrorWithCode(400, "invalid_otp", "invalid or expired OTP")
+	ErrInvalidToken           = NewAppErrorWithCode(401, "invalid_token", "invalid or expired token")
+	ErrAccountSuspended       = NewAppErrorWithCode(403, "account_suspended", "account is suspended")
+	ErrAccountDisabled        = NewAppErrorWithCode(403, "account_disabled", "account is disabled")
+	ErrAccountPendingDeletion = NewAppErrorWithCode(403, "account_pending_deletion", "account is scheduled for deletion")
+	ErrInvalidStatus          = NewAppErrorWithCode(400, "invalid_status", "invalid account status")
+	ErrCannotImpersonate      = NewAppErrorWithCode(403, "cannot_impersonate", "this user cannot be impersonated")
+	ErrForbidden              = NewAppErrorWithCode(403, "forbidden", "you do not have permission to perform this action")
+	ErrCannotManageSelf       = NewAppErrorWithCode(403, "cannot_manage_self", "this action cannot be performed on your own account")
+	ErrOTPChannelUnavailable  = NewAppErrorWithCode(400, "otp_channel_unavailable", "the requested OTP channel is not available")
+	ErrInvalidClient          = NewAppErrorWithCode(400, "invalid_client", "unknown client_id")
+	ErrPhoneNotVerified       = NewAppErrorWithCode(400, "phone_not_verified", "phone number has not been verified")
+	ErrUnsupportedLocale      = NewAppErrorWithCode(400, "unsupported_locale", "no email templates exist for the requested locale")
+	ErrOTPRateLimited         = NewAppErrorWithCode(429, "otp_rate_limited", "too many codes requested, please try again later")
 )
@@ -0,0 +1,17 @@
+package model
+
+import "context"
+
+const operatorKey = ContextKey("operator")
+
+// WithOperator menandai context sebagai operasi operator dari command line (adminctl), yang
+// sudah memiliki akses langsung ke database sehingga tidak dibatasi role.
+func WithOperator(ctx context.Context) context.Context {
+	return context.WithValue(ctx, operatorKey, true)
+}
+
+// IsOperator memeriksa apakah context berasal dari operasi operator.
+func IsOperator(ctx context.Context) bool {
+	operator, _ := ctx.Value(operatorKey).(bool)
+	return operator
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxEvent adalah event domain yang ditulis dalam transaksi yang sama dengan perubahan data,
+// lalu dikirim oleh dispatcher di latar belakang.
+type OutboxEvent struct {
+	ID          string     `gorm:"type:uuid;primaryKey" json:"id"`
+	Type        string     `gorm:"not null;index" json:"type"`
+	Payload     JSONMap    `gorm:"type:jsonb" json:"payload"`
+	Attempts    int        `gorm:"not null;default:0" json:"attempts"`
+	LastError   string     `json:"last_error,omitempty"`
+	AvailableAt time.Time  `gorm:"not null;index" json:"available_at"`
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+	ProcessedAt *time.Time `gorm:"index" json:"processed_at,omitempty"`
+	FailedAt    *time.Time `json:"failed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// EventVerificationRequested adalah event internal yang meminta OTP atau email verifikasi
+// dikirim ke user baru. Event ini diproses oleh AuthService dan tidak dikirim ke webhook.
+const EventVerificationRequested = "internal.verification_requested"
+
+// NewOutboxEvent membuat event outbox baru yang siap dikirim.
+func NewOutboxEvent(eventType string, payload map[string]interface{}) OutboxEvent {
+	now := time.Now().UTC()
+	return OutboxEvent{
+		ID:          uuid.New().String(),
+		Type:        eventType,
+		Payload:     payload,
+		AvailableAt: now,
+		CreatedAt:   now,
+	}
+}
@@ -7,25 +7,123 @@ import (
 	"gorm.io/gorm"
 )
 
+// Role pengguna yang dikenali oleh aplikasi. RoleSuperAdmin memiliki hak istimewa seperti impersonasi.
+const (
+	RoleUser       = "user"
+	RoleAdmin      = "admin"
+	RoleSuperAdmin = "superadmin"
+)
+
+// roleRank mengurutkan role dari hak akses terendah.
+var roleRank = map[string]int{
+	RoleUser:       0,
+	RoleAdmin:      1,
+	RoleSuperAdmin: 2,
+}
+
+// CanManage memeriksa apakah u boleh mengubah akun target. Superadmin boleh mengubah siapa pun;
+// role lain hanya boleh mengubah user dengan role yang lebih rendah.
+func (u *User) CanManage(target *User) bool {
+	return u.Role == RoleSuperAdmin || roleRank[target.Role] < roleRank[u.Role]
+}
+
+// Status akun pengguna.
+const (
+	StatusActive          = "active"
+	StatusSuspended       = "suspended"
+	StatusDisabled        = "disabled"
+	StatusPendingDeletion = "pending_deletion"
+)
+
 type User struct {
-	ID           uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
-	Email        string    `gorm:"uniqueIndex;not null" json:"email"`
-	PasswordHash string    `gorm:"not null" json:"-"`
-	IsVerified   bool      `gorm:"default:false" json:"is_verified"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID              uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	Email           string     `gorm:"uniqueIndex;not null" json:"email"`
+	PasswordHash    string     `gorm:"not null" json:"-"`
+	Role            string     `gorm:"not null;default:user" json:"role"`
+	IsVerified      bool       `gorm:"default:false" json:"is_verified"`
+	Locale          string     `json:"locale,omitempty"`
+	Phone           string     `gorm:"index" json:"phone,omitempty"`
+	PhoneVerified   bool       `gorm:"default:false" json:"phone_verified"`
+	OTPChannel      string     `gorm:"not null;default:email" json:"otp_channel"`
+	Status          string     `gorm:"not null;default:active;index" json:"status"`
+	StatusReason    string     `json:"status_reason,omitempty"`
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty"`
+	SuspendedUntil  *time.Time `json:"suspended_until,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
 	if u.ID == uuid.Nil {
 		u.ID = uuid.New()
 	}
+	if u.Role == "" {
+		u.Role = RoleUser
+	}
+	if u.Status == "" {
+		u.Status = StatusActive
+	}
 	return
 }
 
+// Kolom yang diubah bersama oleh satu operasi, untuk UserStore.Update. Setiap operasi hanya
+// menulis kolomnya sendiri agar tidak menimpa perubahan lain yang terjadi bersamaan.
+var (
+	ColumnsVerified   = []string{"is_verified"}
+	ColumnsPassword   = []string{"password_hash"}
+	ColumnsPhone      = []string{"phone", "phone_verified"}
+	ColumnsOTPChannel = []string{"otp_channel"}
+	ColumnsStatus     = []string{"status", "status_reason", "status_changed_at", "suspended_until"}
+)
+
+// SetStatus mengubah status akun beserta alasan dan waktu perubahannya.
+func (u *User) SetStatus(status, reason string, suspendedUntil *time.Time) {
+	now := time.Now()
+	u.Status = status
+	u.StatusReason = reason
+	u.StatusChangedAt = &now
+	u.SuspendedUntil = nil
+	if status == StatusSuspended {
+		u.SuspendedUntil = suspendedUntil
+	}
+}
+
+// CheckStatus mengembalikan error jika akun tidak boleh mengakses layanan.
+// Suspensi dengan SuspendedUntil yang sudah lewat dianggap sudah berakhir.
+func (u *User) CheckStatus() error {
+	switch u.Status {
+	case "", StatusActive:
+		return nil
+	case StatusSuspended:
+		if u.SuspendedUntil != nil && time.Now().After(*u.SuspendedUntil) {
+			return nil
+		}
+		return ErrAccountSuspended
+	case StatusDisabled:
+		return ErrAccountDisabled
+	case StatusPendingDeletion:
+		return ErrAccountPendingDeletion
+	default:
+		return ErrAccountDisabled
+	}
+}
+
+// IsValidStatus memeriksa apakah status dikenali.
+func IsValidStatus(status string) bool {
+	switch status {
+	case StatusActive, StatusSuspended, StatusDisabled, StatusPendingDeletion:
+		return true
+	}
+	return false
+}
+
+// RegisterInput tidak menerima nomor telepon: OTP pendaftaran selalu dikirim lewat email agar
+// endpoint tanpa autentikasi tidak bisa dipakai mengirim SMS ke nomor sembarang. Nomor telepon
+// ditambahkan dan diverifikasi setelah login.
 type RegisterInput struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=8"`
+	Locale   string `json:"locale" validate:"omitempty,max=35"`
 }
 
 type LoginInput struct {
@@ -47,7 +145,8 @@ type LogoutInput struct {
 }
 
 type ForgotPasswordInput struct {
-	Email string `json:"email" validate:"required,email"`
+	Email    string `json:"email" validate:"required,email"`
+	ClientID string `json:"client_id" validate:"omitempty,max=64"`
 }
 
 type ResetPasswordInput struct {
@@ -55,8 +154,63 @@ type ResetPasswordInput struct {
 	NewPassword string `json:"new_password" validate:"required,min=8"`
 }
 
+type VerifyEmailInput struct {
+	Token string `json:"token" validate:"required"`
+}
+
 type ResendOTPInput struct {
-	Email string `json:"email" validate:"required,email"`
+	Email   string `json:"email" validate:"required,email"`
+	Channel string `json:"channel" validate:"omitempty,oneof=email sms whatsapp"`
+}
+
+type UpdatePhoneInput struct {
+	Phone   string `json:"phone" validate:"required,e164"`
+	Channel string `json:"channel" validate:"omitempty,oneof=sms whatsapp"`
+}
+
+type VerifyPhoneInput struct {
+	OTP string `json:"otp" validate:"required,len=6"`
+}
+
+type UpdateOTPChannelInput struct {
+	Channel string `json:"channel" validate:"required,oneof=email sms whatsapp"`
+}
+
+type UpdateUserStatusInput struct {
+	Status         string     `json:"status" validate:"required,oneof=active suspended disabled pending_deletion"`
+	Reason         string     `json:"reason" validate:"max=500"`
+	SuspendedUntil *time.Time `json:"suspended_until"`
+}
+
+// TokenExchangeGrantType adalah grant type RFC 8693 untuk pertukaran token.
+const (
+	TokenExchangeGrantType = "urn:ietf:params:oauth:grant-type:token-exchange"
+	AccessTokenType        = "urn:ietf:params:oauth:token-type:access_token"
+)
+
+type ImpersonateInput struct {
+	GrantType        string `json:"grant_type" validate:"required,eq=urn:ietf:params:oauth:grant-type:token-exchange"`
+	RequestedSubject string `json:"requested_subject" validate:"required,uuid"`
+	Reason           string `json:"reason" validate:"required,max=500"`
+}
+
+// CreateUserInput dipakai untuk membuat akun langsung oleh operator tanpa alur registrasi.
+type CreateUserInput struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,min=8"`
+	Role     string `json:"role" validate:"omitempty,oneof=user admin superadmin"`
+	Locale   string `json:"locale" validate:"omitempty,max=35"`
+	Verified bool   `json:"verified"`
+}
+
+type SetPasswordInput struct {
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+type ListUsersInput struct {
+	Query    string
+	Page     int
+	PageSize int
 }
 
 type ContextKey string
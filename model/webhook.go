@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// Jenis event yang dikirim melalui webhook.
+const (
+	EventUserRegistered = "user.registered"
+	EventUserVerified   = "user.verified"
+	EventUserLogin      = "user.login"
+	EventPasswordReset  = "password.reset"
+	EventSessionRevoked = "session.revoked"
+	EventUserDeleted    = "user.deleted"
+)
+
+// WebhookEvent adalah payload yang dikirim ke endpoint webhook.
+type WebhookEvent struct {
+	ID        string                 `json:"id"`
+	Type      string                 `json:"type"`
+	CreatedAt time.Time              `json:"created_at"`
+	Data      map[string]interface{} `json:"data"`
+}
+
+// WebhookDelivery mencatat setiap percobaan pengiriman webhook.
+type WebhookDelivery struct {
+	ID         uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	EventID    string    `gorm:"not null;index" json:"event_id"`
+	EventType  string    `gorm:"not null" json:"event_type"`
+	URL        string    `gorm:"not null" json:"url"`
+	Attempt    int       `gorm:"not null" json:"attempt"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	Succeeded  bool      `gorm:"not null;default:false" json:"succeeded"`
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+}
+
+// WebhookDeliveryFilter adalah kriteria pencarian log pengiriman webhook.
+type WebhookDeliveryFilter struct {
+	EventID  string
+	Failed   bool
+	Page     int
+	PageSize int
+}
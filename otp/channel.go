@@ -0,0 +1,97 @@
+// Package otp mengirim kode OTP ke pengguna melalui berbagai channel (email, SMS, WhatsApp).
+package otp
+
+import (
+	"auth-service/config"
+	"auth-service/mailer"
+	"auth-service/model"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Nama channel pengiriman OTP.
+const (
+	ChannelEmail    = "email"
+	ChannelSMS      = "sms"
+	ChannelWhatsApp = "whatsapp"
+)
+
+// ErrChannelUnavailable dikembalikan jika channel tidak dikonfigurasi.
+var ErrChannelUnavailable = errors.New("otp channel is not available")
+
+// Channel mengirimkan kode OTP ke satu tujuan.
+type Channel interface {
+	Deliver(ctx context.Context, dest Destination, code string, validity time.Duration) error
+}
+
+// Destination adalah penerima OTP. Phone dipakai oleh channel SMS dan WhatsApp.
+type Destination struct {
+	User   *model.User
+	Phone  string
+	Locale string
+}
+
+// Dispatcher memilih channel berdasarkan nama.
+type Dispatcher struct {
+	channels map[string]Channel
+}
+
+func NewDispatcher(channels map[string]Channel) *Dispatcher {
+	return &Dispatcher{channels: channels}
+}
+
+// Available memeriksa apakah channel dikonfigurasi.
+func (d *Dispatcher) Available(name string) bool {
+	_, ok := d.channels[name]
+	return ok
+}
+
+// Send mengirim OTP melalui channel yang diminta.
+func (d *Dispatcher) Send(ctx context.Context, channel string, dest Destination, code string, validity time.Duration) error {
+	ch, ok := d.channels[channel]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrChannelUnavailable, channel)
+	}
+	return ch.Deliver(ctx, dest, code, validity)
+}
+
+// New membuat Dispatcher dari konfigurasi. Email selalu tersedia; SMS dan WhatsApp
+// hanya tersedia jika provider-nya dikonfigurasi.
+func New(cfg *config.Config, m mailer.Mailer, templates *mailer.Renderer) (*Dispatcher, error) {
+	channels := map[string]Channel{
+		ChannelEmail: NewEmailChannel(m, templates),
+	}
+
+	sms, err := newProvider(cfg.SMSProvider, ChannelSMS, cfg.SMSHTTPURL, cfg.SMSHTTPToken, cfg.TwilioSMSFrom, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if sms != nil {
+		channels[ChannelSMS] = NewTextChannel(sms, cfg.AppName, cfg.MailDefaultLocale)
+	}
+
+	whatsApp, err := newProvider(cfg.WhatsAppProvider, ChannelWhatsApp, cfg.WhatsAppHTTPURL, cfg.WhatsAppHTTPToken, cfg.TwilioWhatsAppFrom, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if whatsApp != nil {
+		channels[ChannelWhatsApp] = NewTextChannel(whatsApp, cfg.AppName, cfg.MailDefaultLocale)
+	}
+
+	return NewDispatcher(channels), nil
+}
+
+func newProvider(name, channel, httpURL, httpToken, twilioFrom string, cfg *config.Config) (Provider, error) {
+	switch name {
+	case "":
+		return nil, nil
+	case "http":
+		return NewHTTPProvider(httpURL, httpToken, channel, cfg.OTPProviderTimeout), nil
+	case "twilio":
+		return NewTwilioProvider(cfg.TwilioAccountSID, cfg.TwilioAuthToken, twilioFrom, channel == ChannelWhatsApp, cfg.OTPProviderTimeout), nil
+	default:
+		return nil, fmt.Errorf("unknown %s provider %q", channel, name)
+	}
+}
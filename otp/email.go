@@ -0,0 +1,26 @@
+package otp
+
+import (
+	"auth-service/mailer"
+	"context"
+	"fmt"
+	"time"
+)
+
+// EmailChannel mengirim OTP sebagai email dari template.
+type EmailChannel struct {
+	mailer    mailer.Mailer
+	templates *mailer.Renderer
+}
+
+func NewEmailChannel(m mailer.Mailer, templates *mailer.Renderer) *EmailChannel {
+	return &EmailChannel{mailer: m, templates: templates}
+}
+
+func (c *EmailChannel) Deliver(ctx context.Context, dest Destination, code string, validity time.Duration) error {
+	msg, err := c.templates.OTP(dest.User.Email, dest.Locale, code, validity)
+	if err != nil {
+		return fmt.Errorf("could not render OTP email: %w", err)
+	}
+	return c.mailer.Send(ctx, msg)
+}
@@ -0,0 +1,61 @@
+package otp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPProvider mengirim pesan sebagai JSON {"channel","to","message"} ke sebuah URL.
+// Cocok untuk gateway internal maupun stub lokal (lihat cmd/otpstub).
+type HTTPProvider struct {
+	url     string
+	token   string
+	channel string
+	client  *http.Client
+}
+
+func NewHTTPProvider(url, token, channel string, timeout time.Duration) *HTTPProvider {
+	return &HTTPProvider{
+		url:     url,
+		token:   token,
+		channel: channel,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+func (p *HTTPProvider) SendText(ctx context.Context, to, body string) error {
+	payload, err := json.Marshal(map[string]string{
+		"channel": p.channel,
+		"to":      to,
+		"message": body,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not reach %s provider: %w", p.channel, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s provider returned %s", p.channel, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package otp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const twilioAPIBase = "https://api.twilio.com/2010-04-01"
+
+// TwilioProvider mengirim SMS atau WhatsApp melalui Twilio Messaging API.
+// Untuk WhatsApp, nomor pengirim dan penerima diberi prefix "whatsapp:".
+type TwilioProvider struct {
+	accountSID string
+	authToken  string
+	from       string
+	whatsApp   bool
+	client     *http.Client
+}
+
+func NewTwilioProvider(accountSID, authToken, from string, whatsApp bool, timeout time.Duration) *TwilioProvider {
+	return &TwilioProvider{
+		accountSID: accountSID,
+		authToken:  authToken,
+		from:       from,
+		whatsApp:   whatsApp,
+		client:     &http.Client{Timeout: timeout},
+	}
+}
+
+func (p *TwilioProvider) SendText(ctx context.Context, to, body string) error {
+	from := p.from
+	if p.whatsApp {
+		from = "whatsapp:" + from
+		to = "whatsapp:" + to
+	}
+
+	form := url.Values{}
+	form.Set("From", from)
+	form.Set("To", to)
+	form.Set("Body", body)
+
+	endpoint := fmt.Sprintf("%s/Accounts/%s/Messages.json", twilioAPIBase, p.accountSID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(p.accountSID, p.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not reach twilio: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("twilio returned %s: %s (code %d)", resp.Status, apiErr.Message, apiErr.Code)
+	}
+	return nil
+}
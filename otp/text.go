@@ -0,0 +1,48 @@
+package otp
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Provider mengirim pesan teks ke nomor telepon dalam format E.164.
+type Provider interface {
+	SendText(ctx context.Context, to, body string) error
+}
+
+// fallbackLocale dipakai jika locale user maupun locale default tidak memiliki format pesan,
+// karena locale email ditentukan dari direktori template dan bisa berisi locale lain.
+const fallbackLocale = "en"
+
+// messageFormats adalah isi pesan OTP per locale untuk channel berbasis teks.
+var messageFormats = map[string]string{
+	"id": "Kode verifikasi %s Anda: %s. Berlaku %d menit. Jangan berikan kode ini kepada siapa pun.",
+	"en": "Your %s verification code is %s. It expires in %d minutes. Do not share this code with anyone.",
+}
+
+// TextChannel mengirim OTP sebagai pesan teks lewat Provider, dipakai untuk SMS maupun WhatsApp.
+type TextChannel struct {
+	provider      Provider
+	appName       string
+	defaultLocale string
+}
+
+func NewTextChannel(provider Provider, appName, defaultLocale string) *TextChannel {
+	return &TextChannel{provider: provider, appName: appName, defaultLocale: defaultLocale}
+}
+
+func (c *TextChannel) Deliver(ctx context.Context, dest Destination, code string, validity time.Duration) error {
+	if dest.Phone == "" {
+		return fmt.Errorf("%w: no phone number", ErrChannelUnavailable)
+	}
+
+	format, ok := messageFormats[dest.Locale]
+	if !ok {
+		format, ok = messageFormats[c.defaultLocale]
+	}
+	if !ok {
+		format = messageFormats[fallbackLocale]
+	}
+	return c.provider.SendText(ctx, dest.Phone, fmt.Sprintf(format, c.appName, code, int(validity.Minutes())))
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// auditChainLockID adalah kunci advisory lock Postgres yang menyerialkan penambahan entri audit.
+const auditChainLockID = 7_290_001
+
+type AuditRepo struct {
+	DB      *gorm.DB
+	hashKey []byte
+}
+
+// NewAuditRepo membuat AuditRepo yang menandatangani rantai dengan hashKey (AUDIT_HMAC_KEY).
+func NewAuditRepo(db *gorm.DB, hashKey []byte) *AuditRepo {
+	return &AuditRepo{DB: db, hashKey: hashKey}
+}
+
+// Append menambahkan entri ke ujung rantai hash. Penambahan diserialkan dengan advisory lock
+// agar dua entri tidak pernah menunjuk ke PrevHash yang sama.
+func (r *AuditRepo) Append(ctx context.Context, entry *model.AuditLog) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", auditChainLockID).Error; err != nil {
+			return err
+		}
+
+		var last model.AuditLog
+		err := tx.Order("id DESC").Limit(1).Take(&last).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		entry.PrevHash = last.Hash
+		entry.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+		hash, err := entry.ComputeHash(r.hashKey)
+		if err != nil {
+			return err
+		}
+		entry.Hash = hash
+
+		return tx.Create(entry).Error
+	})
+}
+
+func (r *AuditRepo) List(ctx context.Context, filter model.AuditFilter, offset, limit int) ([]model.AuditLog, int64, error) {
+	db := r.DB.WithContext(ctx).Model(&model.AuditLog{})
+	if filter.Event != "" {
+		db = db.Where("event = ?", filter.Event)
+	}
+	if filter.ActorID != "" {
+		db = db.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.SubjectID != "" {
+		db = db.Where("subject_id = ?", filter.SubjectID)
+	}
+	if filter.From != nil {
+		db = db.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		db = db.Where("created_at < ?", *filter.To)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []model.AuditLog
+	if err := db.Order("id DESC").Offset(offset).Limit(limit).Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// FindAfter mengambil entri dengan ID lebih besar dari afterID secara berurutan, dipakai untuk verifikasi rantai.
+func (r *AuditRepo) FindAfter(ctx context.Context, afterID uint64, limit int) ([]model.AuditLog, error) {
+	var entries []model.AuditLog
+	if err := r.DB.WithContext(ctx).Where("id > ?", afterID).Order("id ASC").Limit(limit).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// ListBySubject mengambil entri terbaru milik subject untuk event tertentu.
+func (r *AuditRepo) ListBySubject(ctx context.Context, subjectID string, events []string, limit int) ([]model.AuditLog, error) {
+	var entries []model.AuditLog
+	err := r.DB.WithContext(ctx).
+		Where("subject_id = ? AND event IN ?", subjectID, events).
+		Order("id DESC").
+		Limit(limit).
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
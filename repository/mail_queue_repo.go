@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	mailQueueKey      = "mail:queue"
+	mailRetryKey      = "mail:retry"
+	mailDeadKey       = "mail:dead"
+	mailProcessingKey = "mail:processing"
+
+	// Dead-letter hanya menyimpan job terbaru dan kedaluwarsa jika tidak ada job baru.
+	mailDeadLimit = 1000
+	mailDeadTTL   = 7 * 24 * time.Hour
+
+	mailPromoteBatch = 100
+)
+
+// promoteScript memindahkan job retry yang jatuh tempo ke antrean utama secara atomik.
+var promoteScript = redis.NewScript(`
+local jobs = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+for _, job in ipairs(jobs) do
+	redis.call('ZREM', KEYS[1], job)
+	redis.call('LPUSH', KEYS[2], job)
+end
+return #jobs
+`)
+
+// recoverScript mengembalikan isi list processing milik instance yang heartbeat-nya sudah
+// hilang (crash atau dimatikan paksa) ke antrean utama, lalu menghapus list dari registry.
+var recoverScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[3]) == 1 then
+	return 0
+end
+local moved = 0
+while redis.call('RPOPLPUSH', KEYS[1], KEYS[2]) do
+	moved = moved + 1
+end
+redis.call('HDEL', KEYS[4], KEYS[1])
+return moved
+`)
+
+// MailQueueRepo menyimpan antrean job email di Redis: list untuk job siap kirim,
+// sorted set untuk job yang menunggu retry, dan list dead-letter untuk job yang menyerah.
+// Job yang sedang dikirim berada di list processing milik worker sampai di-ack, sehingga
+// job tidak hilang jika proses mati di tengah pengiriman.
+type MailQueueRepo struct {
+	client *redis.Client
+}
+
+func NewMailQueueRepo(client *redis.Client) *MailQueueRepo {
+	return &MailQueueRepo{client: client}
+}
+
+// MailWorker mengidentifikasi satu worker antrean email milik sebuah instance.
+type MailWorker struct {
+	Instance string
+	ID       int
+}
+
+func (w MailWorker) processingKey() string {
+	return fmt.Sprintf("mail:processing:%s:%d", w.Instance, w.ID)
+}
+
+func heartbeatKey(instance string) string {
+	return "mail:instance:" + instance
+}
+
+func (r *MailQueueRepo) Enqueue(ctx context.Context, job []byte) error {
+	return r.client.LPush(ctx, mailQueueKey, job).Err()
+}
+
+// Heartbeat menandai instance masih hidup selama ttl. List processing milik instance yang
+// heartbeat-nya habis dikembalikan ke antrean oleh Recover.
+func (r *MailQueueRepo) Heartbeat(ctx context.Context, instance string, ttl time.Duration) error {
+	return r.client.Set(ctx, heartbeatKey(instance), 1, ttl).Err()
+}
+
+// StopHeartbeat menghapus heartbeat instance agar job yang tersisa segera dipulihkan.
+func (r *MailQueueRepo) StopHeartbeat(ctx context.Context, instance string) error {
+	return r.client.Del(ctx, heartbeatKey(instance)).Err()
+}
+
+// Dequeue memindahkan job ke list processing worker, menunggu hingga timeout. Job harus
+// diselesaikan dengan Ack, ScheduleRetry atau DeadLetter. Mengembalikan nil tanpa error jika
+// antrean kosong.
+func (r *MailQueueRepo) Dequeue(ctx context.Context, worker MailWorker, timeout time.Duration) ([]byte, error) {
+	if err := r.client.HSet(ctx, mailProcessingKey, worker.processingKey(), heartbeatKey(worker.Instance)).Err(); err != nil {
+		return nil, err
+	}
+
+	job, err := r.client.BLMove(ctx, mailQueueKey, worker.processingKey(), "RIGHT", "LEFT", timeout).Result()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return []byte(job), nil
+}
+
+// Ack menghapus job yang sudah selesai dari list processing worker.
+func (r *MailQueueRepo) Ack(ctx context.Context, worker MailWorker, job []byte) error {
+	return r.client.LRem(ctx, worker.processingKey(), 1, job).Err()
+}
+
+// ScheduleRetry menyimpan updated untuk dikirim ulang setelah waktu at dan melepas job asal
+// dari list processing dalam satu transaksi.
+func (r *MailQueueRepo) ScheduleRetry(ctx context.Context, worker MailWorker, job, updated []byte, at time.Time) error {
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, mailRetryKey, redis.Z{Score: float64(at.Unix()), Member: updated})
+		pipe.LRem(ctx, worker.processingKey(), 1, job)
+		return nil
+	})
+	return err
+}
+
+// DeadLetter menyimpan dead ke list dead-letter dan melepas job asal dari list processing
+// dalam satu transaksi.
+func (r *MailQueueRepo) DeadLetter(ctx context.Context, worker MailWorker, job, dead []byte) error {
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LPush(ctx, mailDeadKey, dead)
+		pipe.LTrim(ctx, mailDeadKey, 0, mailDeadLimit-1)
+		pipe.Expire(ctx, mailDeadKey, mailDeadTTL)
+		pipe.LRem(ctx, worker.processingKey(), 1, job)
+		return nil
+	})
+	return err
+}
+
+// PromoteDue memindahkan job retry yang sudah jatuh tempo kembali ke antrean utama.
+func (r *MailQueueRepo) PromoteDue(ctx context.Context, now time.Time) (int, error) {
+	promoted, err := promoteScript.Run(ctx, r.client, []string{mailRetryKey, mailQueueKey},
+		strconv.FormatInt(now.Unix(), 10), mailPromoteBatch).Int()
+	return promoted, err
+}
+
+// Recover mengembalikan job di list processing milik instance yang sudah mati ke antrean utama.
+func (r *MailQueueRepo) Recover(ctx context.Context) (int, error) {
+	lists, err := r.client.HGetAll(ctx, mailProcessingKey).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	recovered := 0
+	for list, heartbeat := range lists {
+		moved, err := recoverScript.Run(ctx, r.client, []string{list, mailQueueKey, heartbeat, mailProcessingKey}).Int()
+		if err != nil {
+			return recovered, err
+		}
+		recovered += moved
+	}
+	return recovered, nil
+}
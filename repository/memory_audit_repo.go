@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryAuditRepo adalah AuditStore di memori. Entri disimpan berurutan menurut ID.
+type MemoryAuditRepo struct {
+	mu      sync.RWMutex
+	entries []model.AuditLog
+	hashKey []byte
+}
+
+func NewMemoryAuditRepo(hashKey []byte) *MemoryAuditRepo {
+	return &MemoryAuditRepo{hashKey: hashKey}
+}
+
+func (r *MemoryAuditRepo) Append(ctx context.Context, entry *model.AuditLog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry.ID = uint64(len(r.entries)) + 1
+	if len(r.entries) > 0 {
+		entry.PrevHash = r.entries[len(r.entries)-1].Hash
+	}
+	entry.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	hash, err := entry.ComputeHash(r.hashKey)
+	if err != nil {
+		return err
+	}
+	entry.Hash = hash
+
+	r.entries = append(r.entries, *entry)
+	return nil
+}
+
+func (r *MemoryAuditRepo) List(ctx context.Context, filter model.AuditFilter, offset, limit int) ([]model.AuditLog, int64, error) {
+	matched := r.newestFirst(func(entry model.AuditLog) bool {
+		return (filter.Event == "" || entry.Event == filter.Event) &&
+			(filter.ActorID == "" || entry.ActorID == filter.ActorID) &&
+			(filter.SubjectID == "" || entry.SubjectID == filter.SubjectID) &&
+			(filter.From == nil || !entry.CreatedAt.Before(*filter.From)) &&
+			(filter.To == nil || entry.CreatedAt.Before(*filter.To))
+	})
+	return page(matched, offset, limit), int64(len(matched)), nil
+}
+
+func (r *MemoryAuditRepo) FindAfter(ctx context.Context, afterID uint64, limit int) ([]model.AuditLog, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if afterID >= uint64(len(r.entries)) {
+		return nil, nil
+	}
+	entries := append([]model.AuditLog(nil), r.entries[afterID:]...)
+	return page(entries, 0, limit), nil
+}
+
+func (r *MemoryAuditRepo) ListBySubject(ctx context.Context, subjectID string, events []string, limit int) ([]model.AuditLog, error) {
+	wanted := make(map[string]bool, len(events))
+	for _, event := range events {
+		wanted[event] = true
+	}
+	matched := r.newestFirst(func(entry model.AuditLog) bool {
+		return entry.SubjectID == subjectID && wanted[entry.Event]
+	})
+	return page(matched, 0, limit), nil
+}
+
+// newestFirst mengembalikan entri yang cocok dengan match, diurutkan dari ID terbesar.
+func (r *MemoryAuditRepo) newestFirst(match func(model.AuditLog) bool) []model.AuditLog {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var matched []model.AuditLog
+	for i := len(r.entries) - 1; i >= 0; i-- {
+		if match(r.entries[i]) {
+			matched = append(matched, r.entries[i])
+		}
+	}
+	return matched
+}
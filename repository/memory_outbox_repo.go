@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryOutboxRepo adalah OutboxStore di memori.
+type MemoryOutboxRepo struct {
+	mu     sync.Mutex
+	events map[string]*model.OutboxEvent
+}
+
+func NewMemoryOutboxRepo() *MemoryOutboxRepo {
+	return &MemoryOutboxRepo{events: make(map[string]*model.OutboxEvent)}
+}
+
+func (r *MemoryOutboxRepo) Add(ctx context.Context, events ...model.OutboxEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, event := range events {
+		event := event
+		r.events[event.ID] = &event
+	}
+	return nil
+}
+
+func (r *MemoryOutboxRepo) Claim(ctx context.Context, limit int, lease time.Duration) ([]model.OutboxEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now().UTC()
+	var ready []*model.OutboxEvent
+	for _, event := range r.events {
+		if event.ProcessedAt == nil && event.FailedAt == nil && !event.AvailableAt.After(now) &&
+			(event.LockedUntil == nil || event.LockedUntil.Before(now)) {
+			ready = append(ready, event)
+		}
+	}
+	sort.Slice(ready, func(i, j int) bool {
+		return ready[i].CreatedAt.Before(ready[j].CreatedAt)
+	})
+
+	lockedUntil := now.Add(lease)
+	var claimed []model.OutboxEvent
+	for _, event := range page(ready, 0, limit) {
+		event.LockedUntil = &lockedUntil
+		claimed = append(claimed, *event)
+	}
+	return claimed, nil
+}
+
+func (r *MemoryOutboxRepo) MarkProcessed(ctx context.Context, id string) error {
+	return r.update(id, func(event *model.OutboxEvent) {
+		now := time.Now().UTC()
+		event.ProcessedAt = &now
+	})
+}
+
+func (r *MemoryOutboxRepo) MarkRetry(ctx context.Context, id string, attempts int, lastError string, availableAt time.Time) error {
+	return r.update(id, func(event *model.OutboxEvent) {
+		event.Attempts = attempts
+		event.LastError = lastError
+		event.AvailableAt = availableAt
+	})
+}
+
+func (r *MemoryOutboxRepo) MarkFailed(ctx context.Context, id string, attempts int, lastError string) error {
+	return r.update(id, func(event *model.OutboxEvent) {
+		now := time.Now().UTC()
+		event.Attempts = attempts
+		event.LastError = lastError
+		event.FailedAt = &now
+	})
+}
+
+func (r *MemoryOutboxRepo) DeleteFinishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var deleted int64
+	for id, event := range r.events {
+		if (event.ProcessedAt != nil && event.ProcessedAt.Before(before)) ||
+			(event.FailedAt != nil && event.FailedAt.Before(before)) {
+			delete(r.events, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// update mengubah event dan melepas lease-nya, seperti UPDATE pada OutboxRepo.
+func (r *MemoryOutboxRepo) update(id string, fn func(event *model.OutboxEvent)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if event, ok := r.events[id]; ok {
+		fn(event)
+		event.LockedUntil = nil
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryEntry adalah nilai dengan masa berlaku, setara dengan key Redis ber-TTL.
+type memoryEntry struct {
+	value     string
+	expiresAt time.Time
+	attempts  int // tebakan OTP yang salah, atau nilai counter
+}
+
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// phoneOTP menyimpan OTP (value) beserta nomor telepon yang menunggu verifikasi.
+type phoneOTP struct {
+	memoryEntry
+	phone string
+}
+
+// MemoryTokenRepo adalah TokenStore di memori dengan semantik yang sama seperti RedisRepo.
+// Entri kedaluwarsa dibuang saat dibaca.
+type MemoryTokenRepo struct {
+	mu                 sync.Mutex
+	otps               map[string]memoryEntry
+	otpAttempts        map[string]memoryEntry // email -> tebakan salah dalam OTPAttemptWindow
+	otpCooldowns       map[string]memoryEntry
+	otpCounts          map[string]memoryEntry
+	phoneOTPs          map[string]phoneOTP
+	refreshTokens      map[string]memoryEntry // token -> user ID
+	sessions           map[string]map[string]struct{}
+	resetTokens        map[string]memoryEntry // token -> email
+	claimedVerifyLinks map[string]memoryEntry
+}
+
+func NewMemoryTokenRepo() *MemoryTokenRepo {
+	return &MemoryTokenRepo{
+		otps:               make(map[string]memoryEntry),
+		otpAttempts:        make(map[string]memoryEntry),
+		otpCooldowns:       make(map[string]memoryEntry),
+		otpCounts:          make(map[string]memoryEntry),
+		phoneOTPs:          make(map[string]phoneOTP),
+		refreshTokens:      make(map[string]memoryEntry),
+		sessions:           make(map[string]map[string]struct{}),
+		resetTokens:        make(map[string]memoryEntry),
+		claimedVerifyLinks: make(map[string]memoryEntry),
+	}
+}
+
+func (r *MemoryTokenRepo) SaveOTP(ctx context.Context, email, otp string, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.otps[email] = memoryEntry{value: otp, expiresAt: expiry(ttl)}
+	return nil
+}
+
+func (r *MemoryTokenRepo) VerifyOTP(ctx context.Context, email, otp string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	attempts, ok := lookup(r.otpAttempts, email)
+	if !ok {
+		attempts = memoryEntry{expiresAt: expiry(OTPAttemptWindow)}
+	}
+	if attempts.attempts >= OTPMaxAttempts {
+		return false, nil
+	}
+	entry, ok := lookup(r.otps, email)
+	if !ok {
+		return false, nil
+	}
+	if entry.value != otp {
+		if attempts.attempts++; attempts.attempts >= OTPMaxAttempts {
+			delete(r.otps, email)
+		}
+		r.otpAttempts[email] = attempts
+		return false, nil
+	}
+	delete(r.otps, email)
+	delete(r.otpAttempts, email)
+	return true, nil
+}
+
+func (r *MemoryTokenRepo) AcquireOTPCooldown(ctx context.Context, subject string, ttl time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := lookup(r.otpCooldowns, subject); ok {
+		return false, nil
+	}
+	r.otpCooldowns[subject] = memoryEntry{expiresAt: expiry(ttl)}
+	return true, nil
+}
+
+func (r *MemoryTokenRepo) IncrementOTPCount(ctx context.Context, subject string, window time.Duration) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry, ok := lookup(r.otpCounts, subject)
+	if !ok {
+		entry = memoryEntry{expiresAt: expiry(window)}
+	}
+	entry.attempts++
+	r.otpCounts[subject] = entry
+	return int64(entry.attempts), nil
+}
+
+func (r *MemoryTokenRepo) SavePhoneOTP(ctx context.Context, userID, phone, otp string, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.phoneOTPs[userID] = phoneOTP{memoryEntry: memoryEntry{value: otp, expiresAt: expiry(ttl)}, phone: phone}
+	return nil
+}
+
+func (r *MemoryTokenRepo) VerifyPhoneOTP(ctx context.Context, userID, otp string) (string, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry, ok := r.phoneOTPs[userID]
+	if !ok || entry.expired(time.Now()) {
+		return "", false, nil
+	}
+	if entry.value != otp {
+		if entry.attempts++; entry.attempts >= OTPMaxAttempts {
+			delete(r.phoneOTPs, userID)
+		} else {
+			r.phoneOTPs[userID] = entry
+		}
+		return "", false, nil
+	}
+	delete(r.phoneOTPs, userID)
+	return entry.phone, true, nil
+}
+
+func (r *MemoryTokenRepo) SaveRefreshToken(ctx context.Context, userID, token string, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.refreshTokens[token] = memoryEntry{value: userID, expiresAt: expiry(ttl)}
+	if r.sessions[userID] == nil {
+		r.sessions[userID] = make(map[string]struct{})
+	}
+	r.sessions[userID][token] = struct{}{}
+	return nil
+}
+
+func (r *MemoryTokenRepo) GetUserIDByRefreshToken(ctx context.Context, token string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry, ok := lookup(r.refreshTokens, token)
+	if !ok {
+		return "", ErrNotFound
+	}
+	return entry.value, nil
+}
+
+func (r *MemoryTokenRepo) DeleteRefreshToken(ctx context.Context, token string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry, ok := r.refreshTokens[token]
+	if !ok {
+		return nil
+	}
+	delete(r.refreshTokens, token)
+	delete(r.sessions[entry.value], token)
+	return nil
+}
+
+// CountRefreshTokens menghitung refresh token milik user yang belum kedaluwarsa.
+func (r *MemoryTokenRepo) CountRefreshTokens(ctx context.Context, userID string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var count int64
+	for token := range r.sessions[userID] {
+		if _, ok := lookup(r.refreshTokens, token); ok {
+			count++
+		} else {
+			delete(r.sessions[userID], token)
+		}
+	}
+	return count, nil
+}
+
+func (r *MemoryTokenRepo) DeleteAllRefreshTokens(ctx context.Context, userID string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tokens := r.sessions[userID]
+	for token := range tokens {
+		delete(r.refreshTokens, token)
+	}
+	delete(r.sessions, userID)
+	return len(tokens), nil
+}
+
+func (r *MemoryTokenRepo) SaveResetToken(ctx context.Context, token, email string, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.resetTokens[token] = memoryEntry{value: email, expiresAt: expiry(ttl)}
+	return nil
+}
+
+func (r *MemoryTokenRepo) ConsumeResetToken(ctx context.Context, token string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry, ok := lookup(r.resetTokens, token)
+	if !ok {
+		return "", ErrNotFound
+	}
+	delete(r.resetTokens, token)
+	return entry.value, nil
+}
+
+func (r *MemoryTokenRepo) ClaimEmailVerificationToken(ctx context.Context, token string, ttl time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := lookup(r.claimedVerifyLinks, token); ok {
+		return false, nil
+	}
+	r.claimedVerifyLinks[token] = memoryEntry{expiresAt: expiry(ttl)}
+	return true, nil
+}
+
+func (r *MemoryTokenRepo) ReleaseEmailVerificationToken(ctx context.Context, token string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.claimedVerifyLinks, token)
+	return nil
+}
+
+// expiry mengubah TTL menjadi waktu kedaluwarsa. TTL 0 berarti tidak pernah kedaluwarsa, seperti di Redis.
+func expiry(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
+
+// lookup mengambil entri yang masih berlaku dan menghapus entri yang sudah kedaluwarsa.
+func lookup(entries map[string]memoryEntry, key string) (memoryEntry, bool) {
+	entry, ok := entries[key]
+	if !ok {
+		return memoryEntry{}, false
+	}
+	if entry.expired(time.Now()) {
+		delete(entries, key)
+		return memoryEntry{}, false
+	}
+	return entry, true
+}
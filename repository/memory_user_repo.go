@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryUserRepo adalah UserStore di memori. Event outbox diteruskan ke outbox yang diberikan.
+type MemoryUserRepo struct {
+	mu     sync.RWMutex
+	users  map[string]model.User
+	outbox OutboxStore
+}
+
+func NewMemoryUserRepo(outbox OutboxStore) *MemoryUserRepo {
+	return &MemoryUserRepo{users: make(map[string]model.User), outbox: outbox}
+}
+
+func (r *MemoryUserRepo) Create(ctx context.Context, user *model.User, events ...model.OutboxEvent) error {
+	user.BeforeCreate(nil)
+	now := time.Now()
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	user.UpdatedAt = now
+
+	r.mu.Lock()
+	for _, existing := range r.users {
+		if existing.Email == user.Email {
+			r.mu.Unlock()
+			return fmt.Errorf("%w: email %s", ErrDuplicate, user.Email)
+		}
+	}
+	if _, ok := r.users[user.ID.String()]; ok {
+		r.mu.Unlock()
+		return fmt.Errorf("%w: id %s", ErrDuplicate, user.ID)
+	}
+	r.users[user.ID.String()] = *user
+	r.mu.Unlock()
+
+	return r.outbox.Add(ctx, events...)
+}
+
+func (r *MemoryUserRepo) FindByEmail(ctx context.Context, email string) (*model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, user := range r.users {
+		if user.Email == email {
+			return &user, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryUserRepo) FindByID(ctx context.Context, id string) (*model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	user, ok := r.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &user, nil
+}
+
+func (r *MemoryUserRepo) Update(ctx context.Context, user *model.User, columns []string, events ...model.OutboxEvent) error {
+	user.UpdatedAt = time.Now()
+
+	r.mu.Lock()
+	stored, ok := r.users[user.ID.String()]
+	if !ok {
+		r.mu.Unlock()
+		return ErrNotFound
+	}
+	if err := copyColumns(&stored, user, columns); err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	stored.UpdatedAt = user.UpdatedAt
+	r.users[user.ID.String()] = stored
+	r.mu.Unlock()
+
+	return r.outbox.Add(ctx, events...)
+}
+
+func (r *MemoryUserRepo) List(ctx context.Context, query string, offset, limit int) ([]model.User, int64, error) {
+	query = strings.ToLower(query)
+
+	r.mu.RLock()
+	var matched []model.User
+	for _, user := range r.users {
+		if strings.Contains(strings.ToLower(user.Email), query) {
+			matched = append(matched, user)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].CreatedAt.After(matched[j].CreatedAt)
+	})
+	return page(matched, offset, limit), int64(len(matched)), nil
+}
+
+func (r *MemoryUserRepo) Delete(ctx context.Context, user *model.User, events ...model.OutboxEvent) error {
+	r.mu.Lock()
+	delete(r.users, user.ID.String())
+	r.mu.Unlock()
+
+	return r.outbox.Add(ctx, events...)
+}
+
+// copyColumns menyalin kolom columns dari src ke dst, seperti UPDATE ... SET kolom tersebut.
+func copyColumns(dst, src *model.User, columns []string) error {
+	for _, column := range columns {
+		switch column {
+		case "email":
+			dst.Email = src.Email
+		case "password_hash":
+			dst.PasswordHash = src.PasswordHash
+		case "role":
+			dst.Role = src.Role
+		case "is_verified":
+			dst.IsVerified = src.IsVerified
+		case "locale":
+			dst.Locale = src.Locale
+		case "phone":
+			dst.Phone = src.Phone
+		case "phone_verified":
+			dst.PhoneVerified = src.PhoneVerified
+		case "otp_channel":
+			dst.OTPChannel = src.OTPChannel
+		case "status":
+			dst.Status = src.Status
+		case "status_reason":
+			dst.StatusReason = src.StatusReason
+		case "status_changed_at":
+			dst.StatusChangedAt = src.StatusChangedAt
+		case "suspended_until":
+			dst.SuspendedUntil = src.SuspendedUntil
+		default:
+			return fmt.Errorf("unknown user column %q", column)
+		}
+	}
+	return nil
+}
+
+// page memotong hasil yang sudah diurutkan sesuai offset dan limit, seperti OFFSET/LIMIT SQL.
+func page[T any](items []T, offset, limit int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	items = items[offset:]
+	if limit >= 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryWebhookRepo adalah WebhookStore di memori.
+type MemoryWebhookRepo struct {
+	mu         sync.RWMutex
+	deliveries []model.WebhookDelivery
+}
+
+func NewMemoryWebhookRepo() *MemoryWebhookRepo {
+	return &MemoryWebhookRepo{}
+}
+
+func (r *MemoryWebhookRepo) CreateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delivery.ID = uint64(len(r.deliveries)) + 1
+	if delivery.CreatedAt.IsZero() {
+		delivery.CreatedAt = time.Now()
+	}
+	r.deliveries = append(r.deliveries, *delivery)
+	return nil
+}
+
+func (r *MemoryWebhookRepo) ListDeliveries(ctx context.Context, filter model.WebhookDeliveryFilter, offset, limit int) ([]model.WebhookDelivery, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var matched []model.WebhookDelivery
+	for i := len(r.deliveries) - 1; i >= 0; i-- {
+		delivery := r.deliveries[i]
+		if (filter.EventID == "" || delivery.EventID == filter.EventID) && (!filter.Failed || !delivery.Succeeded) {
+			matched = append(matched, delivery)
+		}
+	}
+	return page(matched, offset, limit), int64(len(matched)), nil
+}
+
+func (r *MemoryWebhookRepo) HasSucceeded(ctx context.Context, eventID, url string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, delivery := range r.deliveries {
+		if delivery.EventID == eventID && delivery.URL == url && delivery.Succeeded {
+			return true, nil
+		}
+	}
+	return false, nil
+}
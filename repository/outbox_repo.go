@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type OutboxRepo struct {
+	DB *gorm.DB
+}
+
+func NewOutboxRepo(db *gorm.DB) *OutboxRepo {
+	return &OutboxRepo{DB: db}
+}
+
+// Add menyimpan event yang tidak terikat dengan perubahan data lain.
+func (r *OutboxRepo) Add(ctx context.Context, events ...model.OutboxEvent) error {
+	return insertOutboxEvents(r.DB.WithContext(ctx), events)
+}
+
+// Claim mengambil event yang siap dikirim dan menguncinya selama lease agar tidak diproses dispatcher lain.
+func (r *OutboxRepo) Claim(ctx context.Context, limit int, lease time.Duration) ([]model.OutboxEvent, error) {
+	var events []model.OutboxEvent
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+		err := tx.Raw(`
+			SELECT * FROM outbox_events
+			WHERE processed_at IS NULL AND failed_at IS NULL
+			  AND available_at <= ? AND (locked_until IS NULL OR locked_until < ?)
+			ORDER BY created_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED`, now, now, limit).Scan(&events).Error
+		if err != nil || len(events) == 0 {
+			return err
+		}
+
+		ids := make([]string, len(events))
+		for i := range events {
+			ids[i] = events[i].ID
+		}
+		return tx.Model(&model.OutboxEvent{}).Where("id IN ?", ids).Update("locked_until", now.Add(lease)).Error
+	})
+	return events, err
+}
+
+func (r *OutboxRepo) MarkProcessed(ctx context.Context, id string) error {
+	return r.DB.WithContext(ctx).Model(&model.OutboxEvent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"processed_at": time.Now().UTC(),
+		"locked_until": nil,
+	}).Error
+}
+
+// MarkRetry menjadwalkan ulang event setelah gagal dikirim.
+func (r *OutboxRepo) MarkRetry(ctx context.Context, id string, attempts int, lastError string, availableAt time.Time) error {
+	return r.DB.WithContext(ctx).Model(&model.OutboxEvent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"attempts":     attempts,
+		"last_error":   lastError,
+		"available_at": availableAt,
+		"locked_until": nil,
+	}).Error
+}
+
+// MarkFailed menghentikan pengiriman event yang sudah melewati batas percobaan.
+func (r *OutboxRepo) MarkFailed(ctx context.Context, id string, attempts int, lastError string) error {
+	return r.DB.WithContext(ctx).Model(&model.OutboxEvent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"attempts":     attempts,
+		"last_error":   lastError,
+		"failed_at":    time.Now().UTC(),
+		"locked_until": nil,
+	}).Error
+}
+
+// DeleteFinishedBefore menghapus event yang sudah diproses atau gagal sebelum waktu before.
+func (r *OutboxRepo) DeleteFinishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.DB.WithContext(ctx).
+		Where("processed_at < ? OR failed_at < ?", before, before).
+		Delete(&model.OutboxEvent{})
+	return result.RowsAffected, result.Error
+}
+
+func insertOutboxEvents(tx *gorm.DB, events []model.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return tx.Create(&events).Error
+}
+
+// withOutbox menjalankan fn dan menyimpan events dalam satu transaksi.
+// Tanpa event, fn dijalankan langsung tanpa membuka transaksi.
+func withOutbox(ctx context.Context, db *gorm.DB, events []model.OutboxEvent, fn func(tx *gorm.DB) error) error {
+	if len(events) == 0 {
+		return fn(db.WithContext(ctx))
+	}
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return insertOutboxEvents(tx, events)
+	})
+}
@@ -2,12 +2,74 @@ package repository
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// OTPMaxAttempts adalah jumlah tebakan salah sebelum OTP dibatalkan. Tanpa batas ini OTP 6 digit
+// bisa ditebak habis-habisan selama masa berlakunya.
+const OTPMaxAttempts = 5
+
+// OTPAttemptWindow adalah masa berlaku hitungan tebakan salah OTP akun, dihitung sejak tebakan
+// salah pertama. Hitungan tidak diatur ulang oleh OTP baru, sehingga meminta ulang OTP tidak
+// menambah jatah tebakan.
+const OTPAttemptWindow = time.Hour
+
+// verifyOTPScript mencocokkan OTP akun dan menghapusnya saat cocok. Setiap tebakan salah dihitung
+// di KEYS[2] selama ARGV[3] milidetik; setelah ARGV[2] kali salah OTP dihapus dan OTP baru pun
+// ditolak sampai hitungan kedaluwarsa.
+var verifyOTPScript = redis.NewScript(`
+if tonumber(redis.call('GET', KEYS[2]) or '0') >= tonumber(ARGV[2]) then
+	return 0
+end
+local val = redis.call('GET', KEYS[1])
+if not val then
+	return 0
+end
+if val == ARGV[1] then
+	redis.call('DEL', KEYS[1], KEYS[2])
+	return 1
+end
+local attempts = redis.call('INCR', KEYS[2])
+if attempts == 1 then
+	redis.call('PEXPIRE', KEYS[2], ARGV[3])
+end
+if attempts >= tonumber(ARGV[2]) then
+	redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// verifyPhoneOTPScript seperti verifyOTPScript untuk OTP telepon yang disimpan sebagai hash;
+// mengembalikan nomor telepon jika cocok.
+var verifyPhoneOTPScript = redis.NewScript(`
+local vals = redis.call('HMGET', KEYS[1], 'phone', 'otp')
+if not vals[1] then
+	return false
+end
+if vals[2] == ARGV[1] then
+	redis.call('DEL', KEYS[1])
+	return vals[1]
+end
+if redis.call('HINCRBY', KEYS[1], 'attempts', 1) >= tonumber(ARGV[2]) then
+	redis.call('DEL', KEYS[1])
+end
+return false
+`)
+
+// incrementScript menaikkan counter dan memasang masa berlakunya saat counter baru dibuat.
+var incrementScript = redis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+if count == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return count
+`)
+
 type RedisRepo struct {
 	client *redis.Client
 }
@@ -16,43 +78,119 @@ func NewRedisRepo(client *redis.Client) *RedisRepo {
 	return &RedisRepo{client: client}
 }
 
+// SaveOTP menyimpan OTP akun baru. Hitungan tebakan salah tetap berlaku sampai kedaluwarsa.
 func (r *RedisRepo) SaveOTP(ctx context.Context, email, otp string, ttl time.Duration) error {
-	key := fmt.Sprintf("otp:%s", email)
-	return r.client.Set(ctx, key, otp, ttl).Err()
+	return r.client.Set(ctx, fmt.Sprintf("otp:%s", email), otp, ttl).Err()
 }
 
+// AcquireOTPCooldown menandai bahwa OTP baru saja dikirim ke subject. Mengembalikan false jika
+// masih ada cooldown yang berlaku, sehingga OTP tidak boleh dikirim lagi.
+func (r *RedisRepo) AcquireOTPCooldown(ctx context.Context, subject string, ttl time.Duration) (bool, error) {
+	return r.client.SetNX(ctx, fmt.Sprintf("otp_cooldown:%s", subject), 1, ttl).Result()
+}
+
+// IncrementOTPCount menghitung OTP yang dikirim ke subject dalam jendela waktu window, dimulai
+// dari pengiriman pertama, dan mengembalikan jumlahnya termasuk pengiriman ini.
+func (r *RedisRepo) IncrementOTPCount(ctx context.Context, subject string, window time.Duration) (int64, error) {
+	return incrementScript.Run(ctx, r.client, []string{fmt.Sprintf("otp_count:%s", subject)}, window.Milliseconds()).Int64()
+}
+
+// VerifyOTP mencocokkan OTP akun. OTP dihapus setelah cocok, atau setelah OTPMaxAttempts kali salah.
 func (r *RedisRepo) VerifyOTP(ctx context.Context, email, otp string) (bool, error) {
-	key := fmt.Sprintf("otp:%s", email)
-	val, err := r.client.Get(ctx, key).Result()
-	if err == redis.Nil {
-		return false, nil // OTP tidak ditemukan (salah atau kedaluwarsa)
-	} else if err != nil {
-		return false, err // Error Redis lainnya
+	keys := []string{fmt.Sprintf("otp:%s", email), fmt.Sprintf("otp_attempts:%s", email)}
+	matched, err := verifyOTPScript.Run(ctx, r.client, keys, otp, OTPMaxAttempts, OTPAttemptWindow.Milliseconds()).Int()
+	if err != nil {
+		return false, err
 	}
+	return matched == 1, nil
+}
 
-	if val == otp {
-		// Hapus OTP setelah berhasil diverifikasi
-		r.client.Del(ctx, key)
-		return true, nil
-	}
+// SavePhoneOTP menyimpan nomor telepon yang menunggu verifikasi beserta OTP-nya.
+func (r *RedisRepo) SavePhoneOTP(ctx context.Context, userID, phone, otp string, ttl time.Duration) error {
+	key := fmt.Sprintf("phone_otp:%s", userID)
+	pipe := r.client.TxPipeline()
+	pipe.HSet(ctx, key, "phone", phone, "otp", otp)
+	pipe.Expire(ctx, key, ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
 
-	return false, nil
+// VerifyPhoneOTP mengembalikan nomor telepon yang diverifikasi jika OTP cocok.
+// Kunci dihapus setelah berhasil agar OTP hanya bisa dipakai sekali, atau setelah
+// OTPMaxAttempts kali salah.
+func (r *RedisRepo) VerifyPhoneOTP(ctx context.Context, userID, otp string) (string, bool, error) {
+	key := fmt.Sprintf("phone_otp:%s", userID)
+	phone, err := verifyPhoneOTPScript.Run(ctx, r.client, []string{key}, otp, OTPMaxAttempts).Text()
+	if err == redis.Nil {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+	return phone, true, nil
 }
 
 func (r *RedisRepo) SaveRefreshToken(ctx context.Context, userID, token string, ttl time.Duration) error {
 	key := fmt.Sprintf("refresh:%s", token) // Kunci berdasarkan token itu sendiri
-	return r.client.Set(ctx, key, userID, ttl).Err()
+	sessionsKey := fmt.Sprintf("sessions:%s", userID)
+
+	// Simpan juga token ke dalam set milik user agar semua sesi bisa dicabut sekaligus.
+	pipe := r.client.TxPipeline()
+	pipe.Set(ctx, key, userID, ttl)
+	pipe.SAdd(ctx, sessionsKey, token)
+	pipe.Expire(ctx, sessionsKey, ttl)
+	_, err := pipe.Exec(ctx)
+	return err
 }
 
 // GetUserIDByRefreshToken mengambil User ID yang terkait dengan refresh token.
 func (r *RedisRepo) GetUserIDByRefreshToken(ctx context.Context, token string) (string, error) {
 	key := fmt.Sprintf("refresh:%s", token)
-	return r.client.Get(ctx, key).Result()
+	userID, err := r.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return "", ErrNotFound
+	}
+	return userID, err
 }
 
 func (r *RedisRepo) DeleteRefreshToken(ctx context.Context, token string) error {
 	key := fmt.Sprintf("refresh:%s", token)
-	return r.client.Del(ctx, key).Err()
+	userID, err := r.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	pipe := r.client.TxPipeline()
+	pipe.Del(ctx, key)
+	pipe.SRem(ctx, fmt.Sprintf("sessions:%s", userID), token)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// CountRefreshTokens menghitung jumlah sesi (refresh token) aktif milik user.
+func (r *RedisRepo) CountRefreshTokens(ctx context.Context, userID string) (int64, error) {
+	return r.client.SCard(ctx, fmt.Sprintf("sessions:%s", userID)).Result()
+}
+
+// DeleteAllRefreshTokens mencabut seluruh sesi milik user dan mengembalikan jumlah token yang dihapus.
+func (r *RedisRepo) DeleteAllRefreshTokens(ctx context.Context, userID string) (int, error) {
+	sessionsKey := fmt.Sprintf("sessions:%s", userID)
+	tokens, err := r.client.SMembers(ctx, sessionsKey).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	keys := make([]string, 0, len(tokens)+1)
+	for _, token := range tokens {
+		keys = append(keys, fmt.Sprintf("refresh:%s", token))
+	}
+	keys = append(keys, sessionsKey)
+
+	if err := r.client.Del(ctx, keys...).Err(); err != nil {
+		return 0, err
+	}
+	return len(tokens), nil
 }
 
 func (r *RedisRepo) SaveResetToken(ctx context.Context, token, email string, ttl time.Duration) error {
@@ -60,19 +198,30 @@ func (r *RedisRepo) SaveResetToken(ctx context.Context, token, email string, ttl
 	return r.client.Set(ctx, key, email, ttl).Err()
 }
 
-func (r *RedisRepo) GetEmailByResetToken(ctx context.Context, token string) (string, error) {
+// ConsumeResetToken mengambil email pemilik token reset password sekaligus menghapusnya (GETDEL),
+// sehingga dari beberapa request ResetPassword yang bersamaan hanya satu yang berhasil.
+func (r *RedisRepo) ConsumeResetToken(ctx context.Context, token string) (string, error) {
 	key := fmt.Sprintf("reset:%s", token)
-	email, err := r.client.Get(ctx, key).Result()
-	if err != nil {
-		return "", err
+	email, err := r.client.GetDel(ctx, key).Result()
+	if err == redis.Nil {
+		return "", ErrNotFound
 	}
-	// Catatan: Token ini tidak dihapus di sini.
-	// Service akan memanggil DeleteResetToken setelah password berhasil diubah.
-	return email, nil
+	return email, err
 }
 
-// DeleteResetToken menghapus token reset password dari Redis.
-func (r *RedisRepo) DeleteResetToken(ctx context.Context, token string) error {
-	key := fmt.Sprintf("reset:%s", token)
-	return r.client.Del(ctx, key).Err()
+// ClaimEmailVerificationToken menandai tautan verifikasi email sebagai sudah dipakai.
+// Mengembalikan false jika tautan sudah pernah dipakai, sehingga klik ganda hanya diproses sekali.
+func (r *RedisRepo) ClaimEmailVerificationToken(ctx context.Context, token string, ttl time.Duration) (bool, error) {
+	return r.client.SetNX(ctx, emailVerifyKey(token), 1, ttl).Result()
+}
+
+// ReleaseEmailVerificationToken membatalkan klaim agar tautan bisa dipakai lagi, misalnya jika
+// verifikasi gagal karena error sementara.
+func (r *RedisRepo) ReleaseEmailVerificationToken(ctx context.Context, token string) error {
+	return r.client.Del(ctx, emailVerifyKey(token)).Err()
+}
+
+func emailVerifyKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return fmt.Sprintf("email_verify:%s", hex.EncodeToString(sum[:]))
 }
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// Error yang dikembalikan semua implementasi store, terlepas dari backend-nya.
+var (
+	// ErrNotFound dikembalikan jika data tidak ditemukan atau sudah kedaluwarsa.
+	ErrNotFound = errors.New("record not found")
+	// ErrDuplicate dikembalikan jika data dengan kunci unik yang sama sudah ada.
+	ErrDuplicate = errors.New("duplicate record")
+)
+
+// UserStore menyimpan data pengguna. Implementasi mengembalikan ErrNotFound jika user tidak
+// ditemukan, ErrDuplicate jika email sudah terdaftar, dan menyimpan events ke outbox
+// bersamaan dengan perubahan user. Update hanya menulis kolom yang disebutkan.
+type UserStore interface {
+	Create(ctx context.Context, user *model.User, events ...model.OutboxEvent) error
+	FindByEmail(ctx context.Context, email string) (*model.User, error)
+	FindByID(ctx context.Context, id string) (*model.User, error)
+	Update(ctx context.Context, user *model.User, columns []string, events ...model.OutboxEvent) error
+	List(ctx context.Context, query string, offset, limit int) ([]model.User, int64, error)
+	Delete(ctx context.Context, user *model.User, events ...model.OutboxEvent) error
+}
+
+// TokenStore menyimpan OTP, refresh token dan token sekali pakai yang memiliki masa berlaku.
+// Implementasi mengembalikan ErrNotFound jika token tidak ditemukan atau sudah kedaluwarsa.
+type TokenStore interface {
+	SaveOTP(ctx context.Context, email, otp string, ttl time.Duration) error
+	VerifyOTP(ctx context.Context, email, otp string) (bool, error)
+	AcquireOTPCooldown(ctx context.Context, subject string, ttl time.Duration) (bool, error)
+	IncrementOTPCount(ctx context.Context, subject string, window time.Duration) (int64, error)
+	SavePhoneOTP(ctx context.Context, userID, phone, otp string, ttl time.Duration) error
+	VerifyPhoneOTP(ctx context.Context, userID, otp string) (string, bool, error)
+	SaveRefreshToken(ctx context.Context, userID, token string, ttl time.Duration) error
+	GetUserIDByRefreshToken(ctx context.Context, token string) (string, error)
+	DeleteRefreshToken(ctx context.Context, token string) error
+	CountRefreshTokens(ctx context.Context, userID string) (int64, error)
+	DeleteAllRefreshTokens(ctx context.Context, userID string) (int, error)
+	SaveResetToken(ctx context.Context, token, email string, ttl time.Duration) error
+	ConsumeResetToken(ctx context.Context, token string) (string, error)
+	ClaimEmailVerificationToken(ctx context.Context, token string, ttl time.Duration) (bool, error)
+	ReleaseEmailVerificationToken(ctx context.Context, token string) error
+}
+
+// AuditStore menyimpan audit log berantai hash.
+type AuditStore interface {
+	Append(ctx context.Context, entry *model.AuditLog) error
+	List(ctx context.Context, filter model.AuditFilter, offset, limit int) ([]model.AuditLog, int64, error)
+	FindAfter(ctx context.Context, afterID uint64, limit int) ([]model.AuditLog, error)
+	ListBySubject(ctx context.Context, subjectID string, events []string, limit int) ([]model.AuditLog, error)
+}
+
+// OutboxStore menyimpan event outbox yang menunggu dikirim oleh dispatcher.
+type OutboxStore interface {
+	Add(ctx context.Context, events ...model.OutboxEvent) error
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]model.OutboxEvent, error)
+	MarkProcessed(ctx context.Context, id string) error
+	MarkRetry(ctx context.Context, id string, attempts int, lastError string, availableAt time.Time) error
+	MarkFailed(ctx context.Context, id string, attempts int, lastError string) error
+	DeleteFinishedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// WebhookStore menyimpan log pengiriman webhook.
+type WebhookStore interface {
+	CreateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error
+	ListDeliveries(ctx context.Context, filter model.WebhookDeliveryFilter, offset, limit int) ([]model.WebhookDelivery, int64, error)
+	HasSucceeded(ctx context.Context, eventID, url string) (bool, error)
+}
+
+var (
+	_ UserStore    = (*UserRepo)(nil)
+	_ TokenStore   = (*RedisRepo)(nil)
+	_ AuditStore   = (*AuditRepo)(nil)
+	_ OutboxStore  = (*OutboxRepo)(nil)
+	_ WebhookStore = (*WebhookRepo)(nil)
+)
+
+// Stores adalah kumpulan store yang dipakai service.
+type Stores struct {
+	Users    UserStore
+	Tokens   TokenStore
+	Audit    AuditStore
+	Outbox   OutboxStore
+	Webhooks WebhookStore
+}
+
+// NewStores membuat store yang disimpan di Postgres dan Redis. auditKey adalah kunci HMAC
+// rantai audit log.
+func NewStores(db *gorm.DB, redisClient *redis.Client, auditKey []byte) *Stores {
+	return &Stores{
+		Users:    NewUserRepo(db),
+		Tokens:   NewRedisRepo(redisClient),
+		Audit:    NewAuditRepo(db, auditKey),
+		Outbox:   NewOutboxRepo(db),
+		Webhooks: NewWebhookRepo(db),
+	}
+}
+
+// NewMemoryStores membuat store yang disimpan di memori proses. Data hilang saat proses
+// berhenti, sehingga hanya cocok untuk test dan mode pengembangan tanpa dependency.
+func NewMemoryStores(auditKey []byte) *Stores {
+	outbox := NewMemoryOutboxRepo()
+	return &Stores{
+		Users:    NewMemoryUserRepo(outbox),
+		Tokens:   NewMemoryTokenRepo(),
+		Audit:    NewMemoryAuditRepo(auditKey),
+		Outbox:   outbox,
+		Webhooks: NewMemoryWebhookRepo(),
+	}
+}
@@ -3,10 +3,20 @@ package repository
 import (
 	"auth-service/model"
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 )
 
+// pgUniqueViolation adalah SQLSTATE Postgres untuk pelanggaran constraint unik.
+const pgUniqueViolation = "23505"
+
+// likeEscaper meloloskan karakter khusus pola LIKE agar input pencarian dicocokkan apa adanya.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 type UserRepo struct {
 	DB *gorm.DB
 }
@@ -15,14 +25,17 @@ func NewUserRepo(db *gorm.DB) *UserRepo {
 	return &UserRepo{DB: db}
 }
 
-func (r *UserRepo) Create(ctx context.Context, user *model.User) error {
-	return r.DB.WithContext(ctx).Create(user).Error
+// Create menyimpan user baru. Event outbox yang diberikan ditulis dalam transaksi yang sama.
+func (r *UserRepo) Create(ctx context.Context, user *model.User, events ...model.OutboxEvent) error {
+	return translateUserError(withOutbox(ctx, r.DB, events, func(tx *gorm.DB) error {
+		return tx.Create(user).Error
+	}))
 }
 
 func (r *UserRepo) FindByEmail(ctx context.Context, email string) (*model.User, error) {
 	var user model.User
 	if err := r.DB.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
-		return nil, err
+		return nil, translateUserError(err)
 	}
 	return &user, nil
 }
@@ -30,11 +43,55 @@ func (r *UserRepo) FindByEmail(ctx context.Context, email string) (*model.User,
 func (r *UserRepo) FindByID(ctx context.Context, id string) (*model.User, error) {
 	var user model.User
 	if err := r.DB.WithContext(ctx).Where("id = ?", id).First(&user).Error; err != nil {
-		return nil, err
+		return nil, translateUserError(err)
 	}
 	return &user, nil
 }
 
-func (r *UserRepo) Update(ctx context.Context, user *model.User) error {
-	return r.DB.WithContext(ctx).Save(user).Error
+// Update menyimpan kolom columns milik user beserta updated_at. Event outbox yang diberikan
+// ditulis dalam transaksi yang sama.
+func (r *UserRepo) Update(ctx context.Context, user *model.User, columns []string, events ...model.OutboxEvent) error {
+	columns = append(append([]string{}, columns...), "updated_at")
+	return translateUserError(withOutbox(ctx, r.DB, events, func(tx *gorm.DB) error {
+		return tx.Model(user).Select(columns).Updates(user).Error
+	}))
+}
+
+// List mengembalikan daftar pengguna dengan pencarian email opsional beserta total data.
+func (r *UserRepo) List(ctx context.Context, query string, offset, limit int) ([]model.User, int64, error) {
+	db := r.DB.WithContext(ctx).Model(&model.User{})
+	if query != "" {
+		db = db.Where("email ILIKE ?", "%"+likeEscaper.Replace(query)+"%")
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []model.User
+	if err := db.Order("created_at DESC").Offset(offset).Limit(limit).Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// Delete menghapus user. Event outbox yang diberikan ditulis dalam transaksi yang sama.
+func (r *UserRepo) Delete(ctx context.Context, user *model.User, events ...model.OutboxEvent) error {
+	return withOutbox(ctx, r.DB, events, func(tx *gorm.DB) error {
+		return tx.Delete(user).Error
+	})
+}
+
+// translateUserError mengubah error gorm dan Postgres menjadi ErrNotFound atau ErrDuplicate.
+func translateUserError(err error) error {
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey),
+		errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation:
+		return fmt.Errorf("%w: %v", ErrDuplicate, err)
+	}
+	return err
 }
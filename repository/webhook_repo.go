@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type WebhookRepo struct {
+	DB *gorm.DB
+}
+
+func NewWebhookRepo(db *gorm.DB) *WebhookRepo {
+	return &WebhookRepo{DB: db}
+}
+
+func (r *WebhookRepo) CreateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	return r.DB.WithContext(ctx).Create(delivery).Error
+}
+
+func (r *WebhookRepo) ListDeliveries(ctx context.Context, filter model.WebhookDeliveryFilter, offset, limit int) ([]model.WebhookDelivery, int64, error) {
+	db := r.DB.WithContext(ctx).Model(&model.WebhookDelivery{})
+	if filter.EventID != "" {
+		db = db.Where("event_id = ?", filter.EventID)
+	}
+	if filter.Failed {
+		db = db.Where("succeeded = ?", false)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var deliveries []model.WebhookDelivery
+	if err := db.Order("id DESC").Offset(offset).Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+// HasSucceeded memeriksa apakah event sudah pernah berhasil dikirim ke url, agar retry tidak mengirim ulang.
+func (r *WebhookRepo) HasSucceeded(ctx context.Context, eventID, url string) (bool, error) {
+	var count int64
+	err := r.DB.WithContext(ctx).Model(&model.WebhookDelivery{}).
+		Where("event_id = ? AND url = ? AND succeeded = ?", eventID, url, true).
+		Count(&count).Error
+	return count > 0, err
+}
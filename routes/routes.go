@@ -4,15 +4,22 @@ import (
 	"auth-service/config"
 	"auth-service/controller"
 	"auth-service/middleware"
+	"auth-service/model"
+	"auth-service/repository"
 
 	"github.com/go-chi/chi/v5"
 )
 
-func SetupRoutes(r *chi.Mux, authController *controller.AuthController, cfg *config.Config) {
+func SetupRoutes(r *chi.Mux, authController *controller.AuthController, adminController *controller.AdminController, auditController *controller.AuditController, securityController *controller.SecurityController, healthController *controller.HealthController, userRepo repository.UserStore, cfg *config.Config) {
+	r.Get("/healthz", healthController.Liveness)
+	r.Get("/readyz", healthController.Readiness)
+
 	r.Route("/auth", func(r chi.Router) {
 		r.Post("/register", authController.Register)
 		r.Post("/login", authController.Login)
 		r.Post("/verify-otp", authController.VerifyOTP)
+		r.Get("/verify-email", authController.VerifyEmail)
+		r.Post("/verify-email", authController.ExchangeEmailToken)
 		r.Post("/token/refresh", authController.RefreshToken)
 		r.Post("/forgot-password", authController.ForgotPassword)
 		r.Post("/reset-password", authController.ResetPassword)
@@ -20,9 +27,39 @@ func SetupRoutes(r *chi.Mux, authController *controller.AuthController, cfg *con
 	})
 
 	r.Route("/api", func(r chi.Router) {
-		r.Use(middleware.JWTMiddleware(cfg.JwtSecret))
+		r.Use(middleware.JWTMiddleware(cfg.JWTVerificationSecrets(), userRepo))
 
 		r.Post("/auth/logout", authController.Logout)
 		r.Get("/profile", authController.GetProfile)
+		r.Get("/security/activity", securityController.Activity)
+
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RejectImpersonation)
+
+			r.Post("/phone", authController.UpdatePhone)
+			r.Post("/phone/verify", authController.VerifyPhone)
+			r.Put("/otp-channel", authController.UpdateOTPChannel)
+		})
+	})
+
+	r.Route("/admin", func(r chi.Router) {
+		r.Use(middleware.JWTMiddleware(cfg.JWTVerificationSecrets(), userRepo))
+		r.Use(middleware.RequireRole(model.RoleAdmin, model.RoleSuperAdmin))
+
+		r.Get("/users", adminController.ListUsers)
+		r.Get("/users/{id}", adminController.GetUser)
+		r.Delete("/users/{id}", adminController.DeleteUser)
+		r.Post("/users/{id}/verify", adminController.VerifyUser)
+		r.Put("/users/{id}/status", adminController.UpdateStatus)
+		r.Post("/users/{id}/disable", adminController.DisableUser)
+		r.Post("/users/{id}/enable", adminController.EnableUser)
+		r.Post("/users/{id}/force-password-reset", adminController.ForcePasswordReset)
+		r.Post("/users/{id}/revoke-sessions", adminController.RevokeSessions)
+
+		r.Get("/audit", auditController.List)
+		r.Get("/audit/verify", auditController.Verify)
+		r.Get("/webhooks/deliveries", adminController.ListWebhookDeliveries)
+
+		r.With(middleware.RequireRole(model.RoleSuperAdmin)).Post("/token-exchange", adminController.Impersonate)
 	})
 }
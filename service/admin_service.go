@@ -0,0 +1,352 @@
+package service
+
+import (
+	"auth-service/config"
+	"auth-service/mailer"
+	"auth-service/model"
+	"auth-service/repository"
+	"auth-service/utils"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// AdminService berisi operasi manajemen pengguna untuk tim support.
+type AdminService struct {
+	userRepo      repository.UserStore
+	redisRepo     repository.TokenStore
+	auditService  *AuditService
+	outboxService *OutboxService
+	mailer        mailer.Mailer
+	templates     *mailer.Renderer
+	cfg           *config.Config
+}
+
+func NewAdminService(userRepo repository.UserStore, redisRepo repository.TokenStore, auditService *AuditService, outboxService *OutboxService, mailer mailer.Mailer, templates *mailer.Renderer, cfg *config.Config) *AdminService {
+	return &AdminService{
+		userRepo:      userRepo,
+		redisRepo:     redisRepo,
+		auditService:  auditService,
+		outboxService: outboxService,
+		mailer:        mailer,
+		templates:     templates,
+		cfg:           cfg,
+	}
+}
+
+// UserPage adalah satu halaman hasil pencarian pengguna.
+type UserPage struct {
+	Users    []model.User `json:"users"`
+	Page     int          `json:"page"`
+	PageSize int          `json:"page_size"`
+	Total    int64        `json:"total"`
+}
+
+// UserDetail adalah data pengguna beserta jumlah sesi aktifnya.
+type UserDetail struct {
+	model.User
+	ActiveSessions int64 `json:"active_sessions"`
+}
+
+func (s *AdminService) ListUsers(ctx context.Context, input model.ListUsersInput) (*UserPage, error) {
+	page, pageSize := normalizePage(input.Page, input.PageSize)
+
+	users, total, err := s.userRepo.List(ctx, input.Query, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("could not list users: %w", err)
+	}
+
+	return &UserPage{
+		Users:    users,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	}, nil
+}
+
+func (s *AdminService) GetUser(ctx context.Context, id string) (*UserDetail, error) {
+	user, err := s.findUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.redisRepo.CountRefreshTokens(ctx, user.ID.String())
+	if err != nil {
+		return nil, fmt.Errorf("could not count active sessions: %w", err)
+	}
+
+	return &UserDetail{User: *user, ActiveSessions: sessions}, nil
+}
+
+// CreateUser membuat akun tanpa OTP. Akun yang dibuat dengan Verified langsung dapat login.
+func (s *AdminService) CreateUser(ctx context.Context, input model.CreateUserInput) (*model.User, error) {
+	if _, err := s.userRepo.FindByEmail(ctx, input.Email); err == nil {
+		return nil, model.ErrUserAlreadyExists
+	}
+	if input.Locale != "" && !s.templates.HasLocale(input.Locale) {
+		return nil, model.ErrUnsupportedLocale
+	}
+	if err := checkCanManage(ctx, &model.User{Role: input.Role}); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("could not hash password: %w", err)
+	}
+
+	user := &model.User{
+		ID:           uuid.New(),
+		Email:        input.Email,
+		PasswordHash: string(hashedPassword),
+		Role:         input.Role,
+		IsVerified:   input.Verified,
+		Locale:       input.Locale,
+	}
+	event := model.NewOutboxEvent(model.EventUserRegistered, userEventData(user))
+	err = s.userRepo.Create(ctx, user, event)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return nil, model.ErrUserAlreadyExists
+	} else if err != nil {
+		return nil, fmt.Errorf("could not create user: %w", err)
+	}
+
+	s.auditService.Record(ctx, model.AuditUserCreated, "", user.ID.String(), model.JSONMap{
+		"role":     user.Role,
+		"verified": user.IsVerified,
+	})
+	return user, nil
+}
+
+func (s *AdminService) VerifyUser(ctx context.Context, id string) (*model.User, error) {
+	user, err := s.findManagedUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsVerified {
+		user.IsVerified = true
+		event := model.NewOutboxEvent(model.EventUserVerified, userEventData(user))
+		if err := s.userRepo.Update(ctx, user, model.ColumnsVerified, event); err != nil {
+			return nil, fmt.Errorf("could not update user verification status: %w", err)
+		}
+		s.auditService.Record(ctx, model.AuditUserVerified, "", user.ID.String(), nil)
+	}
+	return user, nil
+}
+
+// SetStatus mengubah status akun. Status selain active juga mencabut semua sesi milik user.
+func (s *AdminService) SetStatus(ctx context.Context, id string, input model.UpdateUserStatusInput) (*model.User, error) {
+	if !model.IsValidStatus(input.Status) {
+		return nil, model.ErrInvalidStatus
+	}
+
+	user, err := s.findManagedUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	previousStatus := user.Status
+	user.SetStatus(input.Status, input.Reason, input.SuspendedUntil)
+	if err := s.userRepo.Update(ctx, user, model.ColumnsStatus); err != nil {
+		return nil, fmt.Errorf("could not update user status: %w", err)
+	}
+	s.auditService.Record(ctx, model.AuditUserStatusChanged, "", user.ID.String(), model.JSONMap{
+		"from":   previousStatus,
+		"to":     input.Status,
+		"reason": input.Reason,
+	})
+
+	if input.Status != model.StatusActive {
+		if _, err := revokeAllSessions(ctx, s.redisRepo, s.outboxService, user, "status_changed"); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+// ForcePasswordReset mencabut semua sesi user dan mengirimkan email reset password.
+func (s *AdminService) ForcePasswordReset(ctx context.Context, id string) error {
+	cfg := s.cfg.Current()
+	user, err := s.findManagedUser(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := revokeAllSessions(ctx, s.redisRepo, s.outboxService, user, "password_reset_forced"); err != nil {
+		return err
+	}
+
+	token := utils.GenerateSecureRandomString(32)
+	if err := s.redisRepo.SaveResetToken(ctx, token, user.Email, cfg.ResetPasswordTokenDuration); err != nil {
+		return fmt.Errorf("could not save reset token: %w", err)
+	}
+	s.auditService.Record(ctx, model.AuditPasswordResetForced, "", user.ID.String(), nil)
+
+	link, _ := resetPasswordLink(cfg, "", token)
+	return sendResetPasswordEmail(ctx, s.mailer, s.templates, user, token, link, cfg.ResetPasswordTokenDuration)
+}
+
+// SetPassword mengganti password user secara langsung dan mencabut semua sesinya.
+func (s *AdminService) SetPassword(ctx context.Context, id string, input model.SetPasswordInput) error {
+	user, err := s.findManagedUser(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("could not hash new password: %w", err)
+	}
+
+	user.PasswordHash = string(hashedPassword)
+	event := model.NewOutboxEvent(model.EventPasswordReset, userEventData(user))
+	if err := s.userRepo.Update(ctx, user, model.ColumnsPassword, event); err != nil {
+		return fmt.Errorf("could not update password: %w", err)
+	}
+	s.auditService.Record(ctx, model.AuditPasswordSet, "", user.ID.String(), nil)
+
+	_, err = revokeAllSessions(ctx, s.redisRepo, s.outboxService, user, "password_set")
+	return err
+}
+
+func (s *AdminService) RevokeSessions(ctx context.Context, id string) (int, error) {
+	user, err := s.findManagedUser(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked, err := revokeAllSessions(ctx, s.redisRepo, s.outboxService, user, "admin")
+	if err != nil {
+		return 0, err
+	}
+	s.auditService.Record(ctx, model.AuditSessionsRevoked, "", user.ID.String(), model.JSONMap{"revoked": revoked})
+	return revoked, nil
+}
+
+// DeleteUser menghapus akun secara permanen beserta seluruh sesinya. Audit log hanya mencatat
+// ID user karena audit_logs tidak dapat diubah setelah ditulis.
+func (s *AdminService) DeleteUser(ctx context.Context, id string) error {
+	user, err := s.findManagedUser(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := revokeAllSessions(ctx, s.redisRepo, s.outboxService, user, "user_deleted"); err != nil {
+		return err
+	}
+	event := model.NewOutboxEvent(model.EventUserDeleted, userEventData(user))
+	if err := s.userRepo.Delete(ctx, user, event); err != nil {
+		return fmt.Errorf("could not delete user: %w", err)
+	}
+
+	s.auditService.Record(ctx, model.AuditUserDeleted, "", user.ID.String(), nil)
+	return nil
+}
+
+// Impersonate menerbitkan access token atas nama user lain untuk admin (actorID).
+// Token tidak disertai refresh token, berumur pendek, dan dicatat di audit log sebelum diterbitkan.
+func (s *AdminService) Impersonate(ctx context.Context, actorID string, input model.ImpersonateInput) (map[string]interface{}, error) {
+	cfg := s.cfg.Current()
+	if input.GrantType != model.TokenExchangeGrantType {
+		return nil, model.NewAppErrorWithCode(400, "unsupported_grant_type", "unsupported grant type")
+	}
+	if input.RequestedSubject == actorID {
+		return nil, model.ErrCannotImpersonate
+	}
+
+	target, err := s.findUser(ctx, input.RequestedSubject)
+	if err != nil {
+		return nil, err
+	}
+	// Admin tidak boleh dipakai sebagai target agar impersonasi tidak menjadi jalan eskalasi hak akses.
+	if target.Role != model.RoleUser {
+		return nil, model.ErrCannotImpersonate
+	}
+	if err := target.CheckStatus(); err != nil {
+		return nil, err
+	}
+
+	duration := cfg.ImpersonationTokenDuration
+	err = s.auditService.Record(ctx, model.AuditImpersonationStarted, actorID, target.ID.String(), model.JSONMap{
+		"reason":     input.Reason,
+		"expires_in": int(duration.Seconds()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := utils.GenerateImpersonationJWT(target.ID.String(), actorID, cfg.JwtSecret, duration)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate access token: %w", err)
+	}
+
+	return map[string]interface{}{
+		"access_token":      accessToken,
+		"issued_token_type": model.AccessTokenType,
+		"token_type":        "Bearer",
+		"expires_in":        int(duration.Seconds()),
+	}, nil
+}
+
+// revokeAllSessions mencabut semua refresh token user dan mengirim event session.revoked jika ada sesi yang dicabut.
+func revokeAllSessions(ctx context.Context, tokens repository.TokenStore, outbox *OutboxService, user *model.User, reason string) (int, error) {
+	revoked, err := tokens.DeleteAllRefreshTokens(ctx, user.ID.String())
+	if err != nil {
+		return 0, fmt.Errorf("could not revoke sessions: %w", err)
+	}
+	if revoked > 0 {
+		data := userEventData(user)
+		data["reason"] = reason
+		outbox.Publish(ctx, model.EventSessionRevoked, data)
+	}
+	return revoked, nil
+}
+
+func (s *AdminService) findUser(ctx context.Context, id string) (*model.User, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, model.ErrUserNotFound
+	}
+
+	user, err := s.userRepo.FindByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, model.ErrUserNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("could not find user: %w", err)
+	}
+	return user, nil
+}
+
+// findManagedUser mencari user yang akan diubah dan memastikan aktor boleh mengelolanya.
+func (s *AdminService) findManagedUser(ctx context.Context, id string) (*model.User, error) {
+	user, err := s.findUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCanManage(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// checkCanManage menolak perubahan atas akun aktor sendiri dan atas user dengan role yang
+// setara atau lebih tinggi, kecuali aktornya superadmin. Operasi operator tidak dibatasi.
+func checkCanManage(ctx context.Context, target *model.User) error {
+	if model.IsOperator(ctx) {
+		return nil
+	}
+	actor, ok := ctx.Value(model.ContextKey("user")).(*model.User)
+	if !ok {
+		return model.ErrForbidden
+	}
+	if actor.ID == target.ID {
+		return model.ErrCannotManageSelf
+	}
+	if !actor.CanManage(target) {
+		return model.ErrForbidden
+	}
+	return nil
+}
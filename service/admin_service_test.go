@@ -0,0 +1,93 @@
+package service
+
+import (
+	"auth-service/model"
+	"context"
+	"errors"
+	"testing"
+)
+
+// createUser membuat user terverifikasi dengan role yang diberikan melalui operator.
+func (e *testEnv) createUser(t *testing.T, email, role string) *model.User {
+	t.Helper()
+	user, err := e.admin.CreateUser(model.WithOperator(context.Background()), model.CreateUserInput{
+		Email:    email,
+		Password: testPassword,
+		Role:     role,
+		Verified: true,
+	})
+	if err != nil {
+		t.Fatalf("CreateUser(%s): %v", email, err)
+	}
+	return user
+}
+
+func actorContext(actor *model.User) context.Context {
+	ctx := context.WithValue(context.Background(), model.ContextKey("userID"), actor.ID.String())
+	return context.WithValue(ctx, model.ContextKey("user"), actor)
+}
+
+func TestAdminCannotManageEqualOrHigherRoles(t *testing.T) {
+	env := newTestEnv(t)
+	admin := env.createUser(t, "admin@example.com", model.RoleAdmin)
+	otherAdmin := env.createUser(t, "other-admin@example.com", model.RoleAdmin)
+	superAdmin := env.createUser(t, "super@example.com", model.RoleSuperAdmin)
+	user := env.createUser(t, testEmail, model.RoleUser)
+	ctx := actorContext(admin)
+
+	for _, target := range []*model.User{otherAdmin, superAdmin} {
+		if _, err := env.admin.RevokeSessions(ctx, target.ID.String()); !errors.Is(err, model.ErrForbidden) {
+			t.Errorf("RevokeSessions(%s): got %v, want ErrForbidden", target.Role, err)
+		}
+		if err := env.admin.DeleteUser(ctx, target.ID.String()); !errors.Is(err, model.ErrForbidden) {
+			t.Errorf("DeleteUser(%s): got %v, want ErrForbidden", target.Role, err)
+		}
+	}
+	if _, err := env.admin.SetStatus(ctx, admin.ID.String(), model.UpdateUserStatusInput{Status: model.StatusDisabled}); !errors.Is(err, model.ErrCannotManageSelf) {
+		t.Errorf("SetStatus on self: got %v, want ErrCannotManageSelf", err)
+	}
+	if _, err := env.admin.CreateUser(ctx, model.CreateUserInput{Email: "new@example.com", Password: testPassword, Role: model.RoleSuperAdmin}); !errors.Is(err, model.ErrForbidden) {
+		t.Errorf("CreateUser superadmin: got %v, want ErrForbidden", err)
+	}
+
+	if _, err := env.admin.SetStatus(ctx, user.ID.String(), model.UpdateUserStatusInput{Status: model.StatusDisabled}); err != nil {
+		t.Errorf("SetStatus on user: %v", err)
+	}
+	if _, err := env.admin.RevokeSessions(actorContext(superAdmin), otherAdmin.ID.String()); err != nil {
+		t.Errorf("RevokeSessions by superadmin: %v", err)
+	}
+	if _, err := env.admin.RevokeSessions(context.Background(), user.ID.String()); !errors.Is(err, model.ErrForbidden) {
+		t.Errorf("RevokeSessions without actor: got %v, want ErrForbidden", err)
+	}
+}
+
+func TestUserUpdateKeepsConcurrentStatusChange(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	user := env.createUser(t, testEmail, model.RoleUser)
+
+	// Salinan lama, seperti yang dimuat alur user sebelum admin mengubah status.
+	stale, err := env.stores.Users.FindByID(ctx, user.ID.String())
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if _, err := env.admin.SetStatus(model.WithOperator(ctx), user.ID.String(), model.UpdateUserStatusInput{Status: model.StatusDisabled}); err != nil {
+		t.Fatalf("SetStatus: %v", err)
+	}
+
+	stale.PasswordHash = "new-hash"
+	if err := env.stores.Users.Update(ctx, stale, model.ColumnsPassword); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	current, err := env.stores.Users.FindByID(ctx, user.ID.String())
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if current.Status != model.StatusDisabled {
+		t.Fatalf("status = %q, want %q", current.Status, model.StatusDisabled)
+	}
+	if current.PasswordHash != "new-hash" {
+		t.Fatal("password hash was not updated")
+	}
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"auth-service/utils"
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+const (
+	auditVerifyBatchSize = 500
+	knownDeviceLookback  = 200
+)
+
+// AuditService mencatat dan memeriksa audit log event keamanan.
+type AuditService struct {
+	auditRepo repository.AuditStore
+	hashKey   []byte
+}
+
+// NewAuditService membuat AuditService. hashKey harus sama dengan kunci yang dipakai auditRepo.
+func NewAuditService(auditRepo repository.AuditStore, hashKey []byte) *AuditService {
+	return &AuditService{auditRepo: auditRepo, hashKey: hashKey}
+}
+
+// AuditPage adalah satu halaman hasil pencarian audit log.
+type AuditPage struct {
+	Entries  []model.AuditLog `json:"entries"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"page_size"`
+	Total    int64            `json:"total"`
+}
+
+// AuditVerification adalah hasil pemeriksaan rantai hash audit log.
+// HeadID dan HeadHash adalah ujung rantai yang sudah diperiksa; simpan di luar database
+// sebagai anchor untuk verifikasi berikutnya.
+type AuditVerification struct {
+	Valid      bool   `json:"valid"`
+	Checked    int    `json:"checked"`
+	HeadID     uint64 `json:"head_id,omitempty"`
+	HeadHash   string `json:"head_hash,omitempty"`
+	BrokenAtID uint64 `json:"broken_at_id,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// Record menambahkan entri ke audit log. Jika actorID kosong, user yang sedang login dipakai sebagai aktor.
+// IP dan user agent diambil dari context request. Kegagalan juga dicatat ke log aplikasi.
+func (s *AuditService) Record(ctx context.Context, event, actorID, subjectID string, details model.JSONMap) error {
+	if actorID == "" {
+		actorID, _ = ctx.Value(model.ContextKey("userID")).(string)
+	}
+
+	client := model.ClientInfoFromContext(ctx)
+	entry := &model.AuditLog{
+		Event:     event,
+		ActorID:   actorID,
+		SubjectID: subjectID,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Details:   details,
+	}
+
+	if err := s.auditRepo.Append(ctx, entry); err != nil {
+		slog.ErrorContext(ctx, "failed to record audit event", "event", event, "error", err)
+		return fmt.Errorf("could not record audit event: %w", err)
+	}
+	return nil
+}
+
+func (s *AuditService) List(ctx context.Context, filter model.AuditFilter) (*AuditPage, error) {
+	page, pageSize := normalizePage(filter.Page, filter.PageSize)
+
+	entries, total, err := s.auditRepo.List(ctx, filter, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("could not list audit log: %w", err)
+	}
+
+	return &AuditPage{
+		Entries:  entries,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	}, nil
+}
+
+// Verify menelusuri seluruh audit log dari awal dan memastikan setiap hash cocok dengan isinya
+// serta menunjuk ke hash entri sebelumnya. Jika anchor diisi, entri anchor harus masih ada
+// dengan hash yang sama.
+func (s *AuditService) Verify(ctx context.Context, anchor *model.AuditAnchor) (*AuditVerification, error) {
+	result := &AuditVerification{Valid: true}
+	var lastID uint64
+	var prevHash string
+
+	for {
+		entries, err := s.auditRepo.FindAfter(ctx, lastID, auditVerifyBatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("could not read audit log: %w", err)
+		}
+		if len(entries) == 0 {
+			if anchor != nil && anchor.ID > lastID {
+				return brokenChain(result, anchor.ID, "anchored entry is missing"), nil
+			}
+			return result, nil
+		}
+
+		for i := range entries {
+			entry := &entries[i]
+			result.Checked++
+
+			if entry.PrevHash != prevHash {
+				return brokenChain(result, entry.ID, "previous hash does not match"), nil
+			}
+			hash, err := entry.ComputeHash(s.hashKey)
+			if err != nil {
+				return nil, fmt.Errorf("could not compute hash for entry %d: %w", entry.ID, err)
+			}
+			if hash != entry.Hash {
+				return brokenChain(result, entry.ID, "entry content does not match its hash"), nil
+			}
+			if anchor != nil && anchor.ID == entry.ID && anchor.Hash != entry.Hash {
+				return brokenChain(result, entry.ID, "entry does not match the anchor"), nil
+			}
+
+			prevHash = entry.Hash
+			lastID = entry.ID
+			result.HeadID = entry.ID
+			result.HeadHash = entry.Hash
+		}
+	}
+}
+
+func brokenChain(result *AuditVerification, id uint64, reason string) *AuditVerification {
+	result.Valid = false
+	result.BrokenAtID = id
+	result.Reason = reason
+	return result
+}
+
+// UserActivity mengembalikan aktivitas keamanan terbaru milik user untuk ditampilkan kepadanya.
+func (s *AuditService) UserActivity(ctx context.Context, userID string, limit int) ([]model.ActivityEntry, error) {
+	_, limit = normalizePage(1, limit)
+
+	events := make([]string, 0, len(model.ActivityTypes))
+	for event := range model.ActivityTypes {
+		events = append(events, event)
+	}
+
+	entries, err := s.auditRepo.ListBySubject(ctx, userID, events, limit)
+	if err != nil {
+		return nil, fmt.Errorf("could not load activity: %w", err)
+	}
+
+	activity := make([]model.ActivityEntry, 0, len(entries))
+	for _, entry := range entries {
+		activity = append(activity, model.ActivityEntry{
+			Type:      model.ActivityTypes[entry.Event],
+			IPAddress: entry.IPAddress,
+			UserAgent: entry.UserAgent,
+			CreatedAt: entry.CreatedAt,
+		})
+	}
+	return activity, nil
+}
+
+// RecordLogin mencatat login berhasil beserta metodenya (password, otp, email_link), ditambah
+// event new_device jika perangkat (keluarga browser dan OS) belum pernah dipakai login oleh
+// user ini dalam knownDeviceLookback login terakhir.
+func (s *AuditService) RecordLogin(ctx context.Context, userID, method string) {
+	device := utils.DeviceName(model.ClientInfoFromContext(ctx).UserAgent)
+	logins, err := s.auditRepo.ListBySubject(ctx, userID, []string{model.AuditLoginSucceeded}, knownDeviceLookback)
+	if err != nil {
+		slog.WarnContext(ctx, "could not check known devices", "subject_id", userID, "error", err)
+	} else if !containsDevice(logins, device) {
+		s.Record(ctx, model.AuditNewDevice, userID, userID, model.JSONMap{"device": device})
+	}
+
+	s.Record(ctx, model.AuditLoginSucceeded, userID, userID, model.JSONMap{"method": method, "device": device})
+}
+
+func containsDevice(logins []model.AuditLog, device string) bool {
+	for _, login := range logins {
+		if utils.DeviceName(login.UserAgent) == device {
+			return true
+		}
+	}
+	return false
+}
@@ -2,78 +2,152 @@ package service
 
 import (
 	"auth-service/config"
+	"auth-service/mailer"
+	"auth-service/metrics"
 	"auth-service/model"
+	"auth-service/otp"
 	"auth-service/repository"
 	"auth-service/utils"
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthService struct {
-	userRepo  *repository.UserRepo
-	redisRepo *repository.RedisRepo
-	cfg       *config.Config
+	userRepo      repository.UserStore
+	redisRepo     repository.TokenStore
+	auditService  *AuditService
+	outboxService *OutboxService
+	mailer        mailer.Mailer
+	templates     *mailer.Renderer
+	otpDispatcher *otp.Dispatcher
+	cfg           *config.Config
 }
 
-func NewAuthService(userRepo *repository.UserRepo, redisRepo *repository.RedisRepo, cfg *config.Config) *AuthService {
-	return &AuthService{
-		userRepo:  userRepo,
-		redisRepo: redisRepo,
-		cfg:       cfg,
+func NewAuthService(userRepo repository.UserStore, redisRepo repository.TokenStore, auditService *AuditService, outboxService *OutboxService, mailer mailer.Mailer, templates *mailer.Renderer, otpDispatcher *otp.Dispatcher, cfg *config.Config) *AuthService {
+	s := &AuthService{
+		userRepo:      userRepo,
+		redisRepo:     redisRepo,
+		auditService:  auditService,
+		outboxService: outboxService,
+		mailer:        mailer,
+		templates:     templates,
+		otpDispatcher: otpDispatcher,
+		cfg:           cfg,
 	}
+	outboxService.Handle(model.EventVerificationRequested, s.handleVerificationRequested)
+	return s
 }
 
-func (s *AuthService) Register(ctx context.Context, input model.RegisterInput) error {
-	_, err := s.userRepo.FindByEmail(ctx, input.Email)
-	if err == nil {
+// Register menyimpan user baru. OTP atau email verifikasi dikirim oleh outbox dispatcher dari
+// event yang ditulis dalam transaksi yang sama, sehingga kegagalan Redis atau mailer tidak
+// meninggalkan akun tanpa verifikasi dan pengiriman dicoba ulang otomatis.
+func (s *AuthService) Register(ctx context.Context, input model.RegisterInput) (err error) {
+	defer func() { metrics.Registrations.WithLabelValues(outcome(err)).Inc() }()
+
+	if _, err := s.userRepo.FindByEmail(ctx, input.Email); err == nil {
 		return model.ErrUserAlreadyExists
 	}
 
+	if !s.otpDispatcher.Available(otp.ChannelEmail) {
+		return model.ErrOTPChannelUnavailable
+	}
+	if input.Locale != "" && !s.templates.HasLocale(input.Locale) {
+		return model.ErrUnsupportedLocale
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return fmt.Errorf("could not hash password: %w", err)
 	}
 
 	user := model.User{
+		ID:           uuid.New(),
 		Email:        input.Email,
 		PasswordHash: string(hashedPassword),
 		IsVerified:   false,
+		Locale:       input.Locale,
+		OTPChannel:   otp.ChannelEmail,
 	}
 
-	if err := s.userRepo.Create(ctx, &user); err != nil {
+	registered := model.NewOutboxEvent(model.EventUserRegistered, userEventData(&user))
+	verification := model.NewOutboxEvent(model.EventVerificationRequested, map[string]interface{}{
+		"user_id": user.ID.String(),
+		"channel": otp.ChannelEmail,
+	})
+	err = s.userRepo.Create(ctx, &user, registered, verification)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return model.ErrUserAlreadyExists
+	} else if err != nil {
 		return fmt.Errorf("could not create user: %w", err)
 	}
+	return nil
+}
+
+// handleVerificationRequested mengirim OTP atau email verifikasi untuk EventVerificationRequested.
+func (s *AuthService) handleVerificationRequested(ctx context.Context, event *model.OutboxEvent) error {
+	userID, _ := event.Payload["user_id"].(string)
+	channel, _ := event.Payload["channel"].(string)
 
-	return s.sendOTP(ctx, user.Email)
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not load user: %w", err)
+	}
+	if user.IsVerified {
+		return nil
+	}
+	return s.sendOTP(ctx, user, channel)
 }
 
-func (s *AuthService) Login(ctx context.Context, input model.LoginInput) (map[string]string, error) {
+func (s *AuthService) Login(ctx context.Context, input model.LoginInput) (tokens map[string]string, err error) {
+	defer func() { metrics.Logins.WithLabelValues(outcome(err)).Inc() }()
+
 	user, err := s.userRepo.FindByEmail(ctx, input.Email)
 	if err != nil {
+		s.auditService.Record(ctx, model.AuditLoginFailed, "", "", model.JSONMap{
+			"email":  input.Email,
+			"reason": "unknown_email",
+		})
 		return nil, model.ErrInvalidCredentials
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(input.Password)); err != nil {
+		s.recordLoginFailure(ctx, user, model.ErrInvalidCredentials)
 		return nil, model.ErrInvalidCredentials
 	}
 
+	if err := user.CheckStatus(); err != nil {
+		s.recordLoginFailure(ctx, user, err)
+		return nil, err
+	}
+
 	if !user.IsVerified {
-		s.sendOTP(ctx, user.Email)
+		s.recordLoginFailure(ctx, user, model.ErrAccountNotVerified)
+		s.sendOTP(ctx, user, "")
 		return nil, model.ErrAccountNotVerified
 	}
 
-	return s.generateTokens(ctx, user)
+	return s.startSession(ctx, user, "password")
 }
 
-func (s *AuthService) VerifyOTP(ctx context.Context, email, otp string) (map[string]string, error) {
+func (s *AuthService) VerifyOTP(ctx context.Context, email, otp string) (tokens map[string]string, err error) {
+	defer func() { metrics.OTPVerifications.WithLabelValues("account", outcome(err)).Inc() }()
+
 	isValid, err := s.redisRepo.VerifyOTP(ctx, email, otp)
 	if err != nil {
 		return nil, fmt.Errorf("could not verify otp from redis: %w", err)
 	}
 	if !isValid {
+		s.auditService.Record(ctx, model.AuditOTPFailed, "", "", model.JSONMap{"email": email})
 		return nil, model.ErrInvalidOTP
 	}
 
@@ -81,18 +155,101 @@ func (s *AuthService) VerifyOTP(ctx context.Context, email, otp string) (map[str
 	if err != nil {
 		return nil, model.ErrUserNotFound
 	}
+	s.auditService.Record(ctx, model.AuditOTPVerified, user.ID.String(), user.ID.String(), nil)
+
+	if err := user.CheckStatus(); err != nil {
+		return nil, err
+	}
 
 	if !user.IsVerified {
 		user.IsVerified = true
-		if err := s.userRepo.Update(ctx, user); err != nil {
+		event := model.NewOutboxEvent(model.EventUserVerified, userEventData(user))
+		if err := s.userRepo.Update(ctx, user, model.ColumnsVerified, event); err != nil {
 			return nil, fmt.Errorf("could not update user verification status: %w", err)
 		}
 	}
 
-	return s.generateTokens(ctx, user)
+	return s.startSession(ctx, user, "otp")
+}
+
+// VerifyEmailLink memverifikasi akun dari tautan di email verifikasi dan langsung menerbitkan token.
+// Setiap tautan hanya bisa dipakai sekali. Tautan baru diklaim setelah status akun diperiksa,
+// dan klaim dilepas lagi jika penyimpanan gagal, sehingga tautan tidak hangus karena error.
+func (s *AuthService) VerifyEmailLink(ctx context.Context, token string) (tokens map[string]string, err error) {
+	cfg := s.cfg.Current()
+	defer func() { metrics.OTPVerifications.WithLabelValues("email_link", outcome(err)).Inc() }()
+
+	user, err := s.emailLinkUser(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	claimed, err := s.redisRepo.ClaimEmailVerificationToken(ctx, token, cfg.EmailVerificationDuration)
+	if err != nil {
+		return nil, fmt.Errorf("could not claim verification token: %w", err)
+	}
+	if !claimed {
+		return nil, model.ErrInvalidToken
+	}
+
+	if err := s.markEmailVerified(ctx, user); err != nil {
+		if releaseErr := s.redisRepo.ReleaseEmailVerificationToken(ctx, token); releaseErr != nil {
+			slog.WarnContext(ctx, "could not release verification token", "error", releaseErr)
+		}
+		return nil, err
+	}
+
+	return s.startSession(ctx, user, "email_link")
 }
 
-func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (map[string]string, error) {
+// ConfirmEmail memverifikasi akun dari tautan tanpa memakai tautan dan tanpa menerbitkan token,
+// sehingga aman dipanggil berulang kali (misalnya oleh pemindai tautan).
+func (s *AuthService) ConfirmEmail(ctx context.Context, token string) (err error) {
+	defer func() { metrics.OTPVerifications.WithLabelValues("email_link", outcome(err)).Inc() }()
+
+	user, err := s.emailLinkUser(ctx, token)
+	if err != nil {
+		return err
+	}
+	return s.markEmailVerified(ctx, user)
+}
+
+// emailLinkUser memeriksa tanda tangan tautan verifikasi dan status akun pemiliknya.
+func (s *AuthService) emailLinkUser(ctx context.Context, token string) (*model.User, error) {
+	cfg := s.cfg.Current()
+	userID, err := utils.ParseEmailVerificationToken(token)
+	if err != nil {
+		return nil, model.ErrInvalidToken
+	}
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, model.ErrInvalidToken
+	}
+	if err := utils.VerifyEmailVerificationToken(cfg.JWTVerificationSecrets(), token, user.Email, time.Now()); err != nil {
+		return nil, model.ErrInvalidToken
+	}
+	if err := user.CheckStatus(); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *AuthService) markEmailVerified(ctx context.Context, user *model.User) error {
+	if user.IsVerified {
+		return nil
+	}
+	user.IsVerified = true
+	event := model.NewOutboxEvent(model.EventUserVerified, userEventData(user))
+	if err := s.userRepo.Update(ctx, user, model.ColumnsVerified, event); err != nil {
+		return fmt.Errorf("could not update user verification status: %w", err)
+	}
+	s.auditService.Record(ctx, model.AuditEmailVerified, user.ID.String(), user.ID.String(), model.JSONMap{"method": "link"})
+	return nil
+}
+
+func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (tokens map[string]string, err error) {
+	defer func() { metrics.TokenRefreshes.WithLabelValues(outcome(err)).Inc() }()
+
 	userID, err := s.redisRepo.GetUserIDByRefreshToken(ctx, refreshToken)
 	if err != nil {
 		return nil, model.ErrInvalidToken
@@ -103,33 +260,74 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (ma
 		return nil, model.ErrInvalidToken
 	}
 
+	if err := user.CheckStatus(); err != nil {
+		s.redisRepo.DeleteRefreshToken(ctx, refreshToken)
+		return nil, err
+	}
+
 	if err := s.redisRepo.DeleteRefreshToken(ctx, refreshToken); err != nil {
-		fmt.Printf("warning: could not delete old refresh token: %v", err)
+		slog.WarnContext(ctx, "could not delete old refresh token", "error", err)
 	}
 
+	s.auditService.Record(ctx, model.AuditTokenRefreshed, user.ID.String(), user.ID.String(), nil)
 	return s.generateTokens(ctx, user)
 }
 
+// Logout mencabut refresh token milik user yang sedang login. Token yang tidak ada atau milik
+// user lain ditolak tanpa mencatat audit atau mengirim event.
 func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
-	return s.redisRepo.DeleteRefreshToken(ctx, refreshToken)
+	userID, _ := ctx.Value(model.ContextKey("userID")).(string)
+	owner, err := s.redisRepo.GetUserIDByRefreshToken(ctx, refreshToken)
+	if errors.Is(err, repository.ErrNotFound) {
+		return model.ErrInvalidToken
+	} else if err != nil {
+		return fmt.Errorf("could not look up refresh token: %w", err)
+	}
+	if userID == "" || owner != userID {
+		return model.ErrInvalidToken
+	}
+
+	if err := s.redisRepo.DeleteRefreshToken(ctx, refreshToken); err != nil {
+		return fmt.Errorf("could not delete refresh token: %w", err)
+	}
+	s.auditService.Record(ctx, model.AuditLogout, "", userID, nil)
+	s.outboxService.Publish(ctx, model.EventSessionRevoked, map[string]interface{}{
+		"user_id": userID,
+		"reason":  "logout",
+	})
+	return nil
 }
 
-func (s *AuthService) ForgotPassword(ctx context.Context, email string) error {
-	user, err := s.userRepo.FindByEmail(ctx, email)
+func (s *AuthService) ForgotPassword(ctx context.Context, input model.ForgotPasswordInput) error {
+	cfg := s.cfg.Current()
+	// client_id divalidasi sebelum mencari user agar respons tidak membocorkan keberadaan email.
+	if _, err := resetPasswordLink(cfg, input.ClientID, ""); err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, input.Email)
 	if err != nil {
 		return nil
 	}
 
 	token := utils.GenerateSecureRandomString(32)
-	if err := s.redisRepo.SaveResetToken(ctx, token, user.Email, s.cfg.ResetPasswordTokenDuration); err != nil {
+	if err := s.redisRepo.SaveResetToken(ctx, token, user.Email, cfg.ResetPasswordTokenDuration); err != nil {
 		return fmt.Errorf("could not save reset token: %w", err)
 	}
+	details := model.JSONMap{}
+	if input.ClientID != "" {
+		details["client_id"] = input.ClientID
+	}
+	s.auditService.Record(ctx, model.AuditPasswordResetRequested, "", user.ID.String(), details)
 
-	return utils.SendResetPasswordEmail(user.Email, token, s.cfg)
+	link, _ := resetPasswordLink(cfg, input.ClientID, token)
+	return sendResetPasswordEmail(ctx, s.mailer, s.templates, user, token, link, cfg.ResetPasswordTokenDuration)
 }
 
 func (s *AuthService) ResetPassword(ctx context.Context, input model.ResetPasswordInput) error {
-	email, err := s.redisRepo.GetEmailByResetToken(ctx, input.Token)
+	// Token langsung dihapus saat dibaca agar tidak bisa dipakai dua kali, termasuk oleh
+	// request yang berjalan bersamaan. Jika langkah berikutnya gagal, user harus meminta token baru.
+	email, err := s.redisRepo.ConsumeResetToken(ctx, input.Token)
 	if err != nil {
 		return model.ErrInvalidToken
 	}
@@ -145,43 +343,242 @@ func (s *AuthService) ResetPassword(ctx context.Context, input model.ResetPasswo
 	}
 
 	user.PasswordHash = string(hashedPassword)
-	if err := s.userRepo.Update(ctx, user); err != nil {
+	event := model.NewOutboxEvent(model.EventPasswordReset, userEventData(user))
+	if err := s.userRepo.Update(ctx, user, model.ColumnsPassword, event); err != nil {
 		return fmt.Errorf("could not update password: %w", err)
 	}
 
-	s.redisRepo.DeleteResetToken(ctx, input.Token)
-	return nil
+	s.auditService.Record(ctx, model.AuditPasswordReset, user.ID.String(), user.ID.String(), nil)
+	// Sesi lama mungkin milik orang yang mengetahui password sebelumnya, sehingga ikut dicabut.
+	_, err = revokeAllSessions(ctx, s.redisRepo, s.outboxService, user, "password_reset")
+	return err
 }
 
-func (s *AuthService) ResendOTP(ctx context.Context, email string) error {
-	user, err := s.userRepo.FindByEmail(ctx, email)
+func (s *AuthService) ResendOTP(ctx context.Context, input model.ResendOTPInput) error {
+	user, err := s.userRepo.FindByEmail(ctx, input.Email)
 	if err != nil {
 		return model.ErrUserNotFound
 	}
 	if user.IsVerified {
 		return model.NewAppError(400, "Account is already verified")
 	}
-	return s.sendOTP(ctx, email)
+	return s.sendOTP(ctx, user, input.Channel)
+}
+
+// UpdatePhone mengirim OTP ke nomor telepon baru. Nomor baru disimpan ke akun setelah VerifyPhone.
+func (s *AuthService) UpdatePhone(ctx context.Context, userID string, input model.UpdatePhoneInput) (err error) {
+	cfg := s.cfg.Current()
+	channel := input.Channel
+	if channel == "" {
+		channel = otp.ChannelSMS
+	}
+	defer func() { metrics.OTPSent.WithLabelValues(channel, outcome(err)).Inc() }()
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return model.ErrUserNotFound
+	}
+	if !s.otpDispatcher.Available(channel) {
+		return model.ErrOTPChannelUnavailable
+	}
+	if err := s.limitPhoneOTP(ctx, user.ID.String(), input.Phone); err != nil {
+		return err
+	}
+
+	code := utils.GenerateOTP(6)
+	if err := s.redisRepo.SavePhoneOTP(ctx, user.ID.String(), input.Phone, code, cfg.OTPDuration); err != nil {
+		return fmt.Errorf("could not save phone OTP: %w", err)
+	}
+	s.auditService.Record(ctx, model.AuditOTPIssued, "", user.ID.String(), model.JSONMap{
+		"channel": channel,
+		"purpose": "phone_verification",
+	})
+
+	dest := otp.Destination{User: user, Phone: input.Phone, Locale: user.Locale}
+	if err := s.otpDispatcher.Send(ctx, channel, dest, code, cfg.OTPDuration); err != nil {
+		return fmt.Errorf("could not send phone OTP: %w", err)
+	}
+	return nil
+}
+
+// VerifyPhone memverifikasi OTP yang dikirim oleh UpdatePhone dan menyimpan nomor ke akun.
+func (s *AuthService) VerifyPhone(ctx context.Context, userID, code string) (err error) {
+	defer func() { metrics.OTPVerifications.WithLabelValues("phone", outcome(err)).Inc() }()
+
+	phone, ok, err := s.redisRepo.VerifyPhoneOTP(ctx, userID, code)
+	if err != nil {
+		return fmt.Errorf("could not verify phone otp from redis: %w", err)
+	}
+	if !ok {
+		s.auditService.Record(ctx, model.AuditOTPFailed, "", userID, model.JSONMap{"purpose": "phone_verification"})
+		return model.ErrInvalidOTP
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return model.ErrUserNotFound
+	}
+	user.Phone = phone
+	user.PhoneVerified = true
+	if err := s.userRepo.Update(ctx, user, model.ColumnsPhone); err != nil {
+		return fmt.Errorf("could not update phone: %w", err)
+	}
+
+	s.auditService.Record(ctx, model.AuditPhoneVerified, "", user.ID.String(), nil)
+	return nil
+}
+
+// UpdateOTPChannel mengubah channel OTP default. SMS dan WhatsApp membutuhkan nomor yang sudah diverifikasi.
+func (s *AuthService) UpdateOTPChannel(ctx context.Context, userID, channel string) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return model.ErrUserNotFound
+	}
+	if !s.otpDispatcher.Available(channel) {
+		return model.ErrOTPChannelUnavailable
+	}
+	if channel != otp.ChannelEmail && !user.PhoneVerified {
+		return model.ErrPhoneNotVerified
+	}
+
+	user.OTPChannel = channel
+	if err := s.userRepo.Update(ctx, user, model.ColumnsOTPChannel); err != nil {
+		return fmt.Errorf("could not update otp channel: %w", err)
+	}
+	return nil
 }
 
 // --- Helper Functions ---
 
-func (s *AuthService) sendOTP(ctx context.Context, email string) error {
-	otp := utils.GenerateOTP(6)
-	if err := s.redisRepo.SaveOTP(ctx, email, otp, s.cfg.OTPDuration); err != nil {
+// sendOTP membuat OTP verifikasi akun dan mengirimkannya lewat channel yang diminta,
+// atau channel default user jika kosong.
+func (s *AuthService) sendOTP(ctx context.Context, user *model.User, channel string) (err error) {
+	cfg := s.cfg.Current()
+	defer func() { metrics.OTPSent.WithLabelValues(channel, outcome(err)).Inc() }()
+
+	if channel == "" {
+		channel = user.OTPChannel
+	}
+	if channel == "" {
+		channel = otp.ChannelEmail
+	}
+	// SMS dan WhatsApp hanya ke nomor yang sudah diverifikasi pemilik akun.
+	if channel != otp.ChannelEmail && !user.PhoneVerified {
+		return model.ErrPhoneNotVerified
+	}
+	if !s.otpDispatcher.Available(channel) {
+		return model.ErrOTPChannelUnavailable
+	}
+	if err := s.acquireOTPCooldown(ctx, "verify:"+user.ID.String()); err != nil {
+		return err
+	}
+	if channel == otp.ChannelEmail && cfg.EmailVerificationMode != "otp" {
+		return s.sendVerificationEmail(ctx, user)
+	}
+
+	code := utils.GenerateOTP(6)
+	if err := s.redisRepo.SaveOTP(ctx, user.Email, code, cfg.OTPDuration); err != nil {
 		return fmt.Errorf("could not save OTP: %w", err)
 	}
-	return utils.SendOTPEmail(email, otp, s.cfg)
+	s.auditService.Record(ctx, model.AuditOTPIssued, "", user.ID.String(), model.JSONMap{"channel": channel})
+
+	dest := otp.Destination{User: user, Phone: user.Phone, Locale: user.Locale}
+	if err := s.otpDispatcher.Send(ctx, channel, dest, code, cfg.OTPDuration); err != nil {
+		return fmt.Errorf("could not send OTP: %w", err)
+	}
+	return nil
+}
+
+// sendVerificationEmail mengirim email verifikasi berisi tautan bertanda tangan. Pada mode "both"
+// email juga memuat OTP sehingga pengguna bisa memilih salah satunya.
+func (s *AuthService) sendVerificationEmail(ctx context.Context, user *model.User) error {
+	cfg := s.cfg.Current()
+	var code string
+	if cfg.EmailVerificationMode == "both" {
+		code = utils.GenerateOTP(6)
+		if err := s.redisRepo.SaveOTP(ctx, user.Email, code, cfg.OTPDuration); err != nil {
+			return fmt.Errorf("could not save OTP: %w", err)
+		}
+	}
+
+	token := utils.SignEmailVerificationToken(cfg.JwtSecret, user.ID.String(), user.Email, time.Now().Add(cfg.EmailVerificationDuration))
+	link := cfg.AppBaseURL + "/auth/verify-email?token=" + url.QueryEscape(token)
+	s.auditService.Record(ctx, model.AuditVerificationLinkSent, "", user.ID.String(), model.JSONMap{
+		"mode": cfg.EmailVerificationMode,
+	})
+
+	msg, err := s.templates.VerifyEmail(user.Email, user.Locale, code, cfg.OTPDuration, link, cfg.EmailVerificationDuration)
+	if err != nil {
+		return fmt.Errorf("could not render verification email: %w", err)
+	}
+	return s.mailer.Send(ctx, msg)
+}
+
+// acquireOTPCooldown menolak pengiriman OTP ke subject yang sama sebelum OTPResendCooldown berlalu.
+func (s *AuthService) acquireOTPCooldown(ctx context.Context, subject string) error {
+	cooldown := s.cfg.Current().OTPResendCooldown
+	if cooldown <= 0 {
+		return nil
+	}
+	ok, err := s.redisRepo.AcquireOTPCooldown(ctx, subject, cooldown)
+	if err != nil {
+		return fmt.Errorf("could not check OTP cooldown: %w", err)
+	}
+	if !ok {
+		return model.ErrOTPRateLimited
+	}
+	return nil
+}
+
+// limitPhoneOTP membatasi OTP verifikasi telepon per user dan per nomor tujuan dengan cooldown
+// dan batas harian, agar endpoint ini tidak bisa dipakai untuk mengirim SMS tanpa batas.
+func (s *AuthService) limitPhoneOTP(ctx context.Context, userID, phone string) error {
+	subjects := []string{"phone_user:" + userID, "phone_number:" + phone}
+	for _, subject := range subjects {
+		if err := s.acquireOTPCooldown(ctx, subject); err != nil {
+			return err
+		}
+	}
+
+	limit := int64(s.cfg.Current().PhoneOTPDailyLimit)
+	for _, subject := range subjects {
+		count, err := s.redisRepo.IncrementOTPCount(ctx, subject, 24*time.Hour)
+		if err != nil {
+			return fmt.Errorf("could not count phone OTPs: %w", err)
+		}
+		if count > limit {
+			return model.ErrOTPRateLimited
+		}
+	}
+	return nil
+}
+
+func (s *AuthService) recordLoginFailure(ctx context.Context, user *model.User, reason error) {
+	details := model.JSONMap{"email": user.Email}
+	var appErr *model.AppError
+	if errors.As(reason, &appErr) {
+		details["reason"] = appErr.Code
+	}
+	s.auditService.Record(ctx, model.AuditLoginFailed, "", user.ID.String(), details)
+}
+
+// startSession mencatat login berhasil lalu menerbitkan token. Semua alur yang membuat sesi baru
+// (password, OTP, tautan email) harus lewat sini agar login tercatat secara konsisten.
+func (s *AuthService) startSession(ctx context.Context, user *model.User, method string) (map[string]string, error) {
+	s.auditService.RecordLogin(ctx, user.ID.String(), method)
+	s.outboxService.Publish(ctx, model.EventUserLogin, userEventData(user))
+	return s.generateTokens(ctx, user)
 }
 
 func (s *AuthService) generateTokens(ctx context.Context, user *model.User) (map[string]string, error) {
-	accessToken, err := utils.GenerateJWT(user.ID.String(), s.cfg.JwtSecret, s.cfg.AccessTokenDuration)
+	cfg := s.cfg.Current()
+	accessToken, err := utils.GenerateJWT(user.ID.String(), cfg.JwtSecret, cfg.AccessTokenDuration)
 	if err != nil {
 		return nil, fmt.Errorf("could not generate access token: %w", err)
 	}
 
 	refreshToken := uuid.New().String()
-	if err := s.redisRepo.SaveRefreshToken(ctx, refreshToken, user.ID.String(), s.cfg.RefreshTokenDuration); err != nil {
+	if err := s.redisRepo.SaveRefreshToken(ctx, user.ID.String(), refreshToken, cfg.RefreshTokenDuration); err != nil {
 		return nil, fmt.Errorf("could not save refresh token: %w", err)
 	}
 
@@ -0,0 +1,364 @@
+package service
+
+import (
+	"auth-service/config"
+	"auth-service/mailer"
+	"auth-service/model"
+	"auth-service/otp"
+	"auth-service/repository"
+	"auth-service/utils"
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testEmail    = "user@example.com"
+	testPassword = "correct-horse"
+	testAuditKey = "test-audit-hmac-key-0123456789abcdef"
+)
+
+var (
+	otpPattern        = regexp.MustCompile(`\b\d{6}\b`)
+	resetTokenPattern = regexp.MustCompile(`\b[0-9a-f]{64}\b`)
+)
+
+// recordingMailer menyimpan email yang dikirim agar test dapat membaca OTP dan token.
+type recordingMailer struct {
+	mu   sync.Mutex
+	sent []mailer.Message
+}
+
+func (m *recordingMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// last mengembalikan isi teks email terakhir yang dikirim ke to.
+func (m *recordingMailer) last(t *testing.T, to string) string {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.sent) - 1; i >= 0; i-- {
+		if m.sent[i].To == to {
+			return m.sent[i].TextBody
+		}
+	}
+	t.Fatalf("no email sent to %s", to)
+	return ""
+}
+
+type testEnv struct {
+	auth   *AuthService
+	admin  *AdminService
+	outbox *OutboxService
+	stores *repository.Stores
+	mail   *recordingMailer
+	cfg    *config.Config
+}
+
+func newTestEnv(t *testing.T, args ...string) *testEnv {
+	t.Helper()
+	args = append([]string{
+		"-storage-driver=memory",
+		"-jwt-secret=test-jwt-secret",
+		"-audit-hmac-key=" + testAuditKey,
+		"-mail-driver=log",
+		"-mail-from=noreply@example.com",
+		"-app-base-url=http://localhost:8080",
+	}, args...)
+	cfg, err := config.Load(flag.NewFlagSet("test", flag.ContinueOnError), args)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+
+	stores := repository.NewMemoryStores([]byte(testAuditKey))
+	mail := &recordingMailer{}
+	templates, err := mailer.NewRenderer("", cfg.AppName, cfg.MailDefaultLocale)
+	if err != nil {
+		t.Fatalf("load templates: %v", err)
+	}
+	dispatcher, err := otp.New(cfg, mail, templates)
+	if err != nil {
+		t.Fatalf("otp dispatcher: %v", err)
+	}
+
+	auditService := NewAuditService(stores.Audit, []byte(testAuditKey))
+	outboxService := NewOutboxService(stores.Outbox, NewWebhookService(stores.Webhooks, cfg), cfg)
+	auth := NewAuthService(stores.Users, stores.Tokens, auditService, outboxService, mail, templates, dispatcher, cfg)
+	admin := NewAdminService(stores.Users, stores.Tokens, auditService, outboxService, mail, templates, cfg)
+	return &testEnv{auth: auth, admin: admin, outbox: outboxService, stores: stores, mail: mail, cfg: cfg}
+}
+
+// register mendaftarkan testEmail dan memproses outbox sehingga email verifikasi terkirim.
+func (e *testEnv) register(t *testing.T) {
+	t.Helper()
+	ctx := context.Background()
+	if err := e.auth.Register(ctx, model.RegisterInput{Email: testEmail, Password: testPassword}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	e.outbox.dispatchBatch(ctx)
+}
+
+// registerVerified mendaftarkan testEmail dan memverifikasinya dengan OTP.
+func (e *testEnv) registerVerified(t *testing.T) map[string]string {
+	t.Helper()
+	e.register(t)
+	code := otpPattern.FindString(e.mail.last(t, testEmail))
+	tokens, err := e.auth.VerifyOTP(context.Background(), testEmail, code)
+	if err != nil {
+		t.Fatalf("VerifyOTP: %v", err)
+	}
+	return tokens
+}
+
+// verifiedUserID mendaftarkan dan memverifikasi testEmail lalu mengembalikan ID-nya.
+func (e *testEnv) verifiedUserID(t *testing.T) string {
+	t.Helper()
+	e.registerVerified(t)
+	user, err := e.stores.Users.FindByEmail(context.Background(), testEmail)
+	if err != nil {
+		t.Fatalf("FindByEmail: %v", err)
+	}
+	return user.ID.String()
+}
+
+func TestRegisterAndVerifyOTP(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	env.register(t)
+
+	if err := env.auth.Register(ctx, model.RegisterInput{Email: testEmail, Password: testPassword}); !errors.Is(err, model.ErrUserAlreadyExists) {
+		t.Fatalf("second Register: got %v, want ErrUserAlreadyExists", err)
+	}
+
+	code := otpPattern.FindString(env.mail.last(t, testEmail))
+	if code == "" {
+		t.Fatal("verification email contains no OTP")
+	}
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+	if _, err := env.auth.VerifyOTP(ctx, testEmail, wrong); !errors.Is(err, model.ErrInvalidOTP) {
+		t.Fatalf("VerifyOTP with wrong code: got %v, want ErrInvalidOTP", err)
+	}
+
+	tokens, err := env.auth.VerifyOTP(ctx, testEmail, code)
+	if err != nil {
+		t.Fatalf("VerifyOTP: %v", err)
+	}
+	if tokens["access_token"] == "" || tokens["refresh_token"] == "" {
+		t.Fatalf("VerifyOTP returned incomplete tokens: %v", tokens)
+	}
+
+	user, err := env.stores.Users.FindByEmail(ctx, testEmail)
+	if err != nil {
+		t.Fatalf("FindByEmail: %v", err)
+	}
+	if !user.IsVerified {
+		t.Fatal("user is not verified after VerifyOTP")
+	}
+
+	if _, err := env.auth.VerifyOTP(ctx, testEmail, code); !errors.Is(err, model.ErrInvalidOTP) {
+		t.Fatalf("reused OTP: got %v, want ErrInvalidOTP", err)
+	}
+}
+
+func TestLogin(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	env.register(t)
+
+	if _, err := env.auth.Login(ctx, model.LoginInput{Email: testEmail, Password: testPassword}); !errors.Is(err, model.ErrAccountNotVerified) {
+		t.Fatalf("Login before verification: got %v, want ErrAccountNotVerified", err)
+	}
+
+	code := otpPattern.FindString(env.mail.last(t, testEmail))
+	if _, err := env.auth.VerifyOTP(ctx, testEmail, code); err != nil {
+		t.Fatalf("VerifyOTP: %v", err)
+	}
+
+	if _, err := env.auth.Login(ctx, model.LoginInput{Email: testEmail, Password: "wrong-password"}); !errors.Is(err, model.ErrInvalidCredentials) {
+		t.Fatalf("Login with wrong password: got %v, want ErrInvalidCredentials", err)
+	}
+	if _, err := env.auth.Login(ctx, model.LoginInput{Email: "nobody@example.com", Password: testPassword}); !errors.Is(err, model.ErrInvalidCredentials) {
+		t.Fatalf("Login with unknown email: got %v, want ErrInvalidCredentials", err)
+	}
+
+	tokens, err := env.auth.Login(ctx, model.LoginInput{Email: testEmail, Password: testPassword})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if tokens["access_token"] == "" || tokens["refresh_token"] == "" {
+		t.Fatalf("Login returned incomplete tokens: %v", tokens)
+	}
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	first := env.registerVerified(t)
+
+	second, err := env.auth.RefreshToken(ctx, first["refresh_token"])
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+	if second["refresh_token"] == "" || second["refresh_token"] == first["refresh_token"] {
+		t.Fatalf("RefreshToken did not rotate the refresh token: %v", second)
+	}
+
+	if _, err := env.auth.RefreshToken(ctx, first["refresh_token"]); !errors.Is(err, model.ErrInvalidToken) {
+		t.Fatalf("reused refresh token: got %v, want ErrInvalidToken", err)
+	}
+	if _, err := env.auth.RefreshToken(ctx, second["refresh_token"]); err != nil {
+		t.Fatalf("RefreshToken with rotated token: %v", err)
+	}
+}
+
+func TestResetPasswordIsSingleUse(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	session := env.registerVerified(t)
+
+	if err := env.auth.ForgotPassword(ctx, model.ForgotPasswordInput{Email: testEmail}); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+	token := resetTokenPattern.FindString(env.mail.last(t, testEmail))
+	if token == "" {
+		t.Fatal("reset email contains no token")
+	}
+
+	const newPassword = "battery-staple"
+	if err := env.auth.ResetPassword(ctx, model.ResetPasswordInput{Token: token, NewPassword: newPassword}); err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+	if err := env.auth.ResetPassword(ctx, model.ResetPasswordInput{Token: token, NewPassword: "another-password"}); !errors.Is(err, model.ErrInvalidToken) {
+		t.Fatalf("reused reset token: got %v, want ErrInvalidToken", err)
+	}
+	if _, err := env.auth.RefreshToken(ctx, session["refresh_token"]); !errors.Is(err, model.ErrInvalidToken) {
+		t.Fatalf("RefreshToken after ResetPassword: got %v, want ErrInvalidToken", err)
+	}
+
+	if _, err := env.auth.Login(ctx, model.LoginInput{Email: testEmail, Password: testPassword}); !errors.Is(err, model.ErrInvalidCredentials) {
+		t.Fatalf("Login with old password: got %v, want ErrInvalidCredentials", err)
+	}
+	if _, err := env.auth.Login(ctx, model.LoginInput{Email: testEmail, Password: newPassword}); err != nil {
+		t.Fatalf("Login with new password: %v", err)
+	}
+}
+
+func TestVerifyEmailLinkCannotBeClaimedTwice(t *testing.T) {
+	env := newTestEnv(t, "-email-verification-mode=link")
+	ctx := context.Background()
+	env.register(t)
+
+	user, err := env.stores.Users.FindByEmail(ctx, testEmail)
+	if err != nil {
+		t.Fatalf("FindByEmail: %v", err)
+	}
+	token := utils.SignEmailVerificationToken(env.cfg.JwtSecret, user.ID.String(), user.Email, time.Now().Add(time.Hour))
+
+	tokens, err := env.auth.VerifyEmailLink(ctx, token)
+	if err != nil {
+		t.Fatalf("VerifyEmailLink: %v", err)
+	}
+	if tokens["refresh_token"] == "" {
+		t.Fatalf("VerifyEmailLink returned incomplete tokens: %v", tokens)
+	}
+
+	if _, err := env.auth.VerifyEmailLink(ctx, token); !errors.Is(err, model.ErrInvalidToken) {
+		t.Fatalf("second VerifyEmailLink: got %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestLogoutOnlyRevokesOwnToken(t *testing.T) {
+	env := newTestEnv(t)
+	tokens := env.registerVerified(t)
+	user, err := env.stores.Users.FindByEmail(context.Background(), testEmail)
+	if err != nil {
+		t.Fatalf("FindByEmail: %v", err)
+	}
+	ctx := context.WithValue(context.Background(), model.ContextKey("userID"), user.ID.String())
+	other := context.WithValue(context.Background(), model.ContextKey("userID"), "00000000-0000-0000-0000-000000000000")
+
+	if err := env.auth.Logout(ctx, "unknown-token"); !errors.Is(err, model.ErrInvalidToken) {
+		t.Fatalf("Logout with unknown token: got %v, want ErrInvalidToken", err)
+	}
+	if err := env.auth.Logout(other, tokens["refresh_token"]); !errors.Is(err, model.ErrInvalidToken) {
+		t.Fatalf("Logout with another user's token: got %v, want ErrInvalidToken", err)
+	}
+	if err := env.auth.Logout(ctx, tokens["refresh_token"]); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+	if _, err := env.auth.RefreshToken(ctx, tokens["refresh_token"]); !errors.Is(err, model.ErrInvalidToken) {
+		t.Fatalf("RefreshToken after Logout: got %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestResendOTPKeepsAttemptLimit(t *testing.T) {
+	env := newTestEnv(t, "-otp-resend-cooldown=0")
+	ctx := context.Background()
+	env.register(t)
+
+	for i := 0; i < repository.OTPMaxAttempts-1; i++ {
+		if _, err := env.auth.VerifyOTP(ctx, testEmail, "wrong"); !errors.Is(err, model.ErrInvalidOTP) {
+			t.Fatalf("VerifyOTP with wrong code: got %v, want ErrInvalidOTP", err)
+		}
+	}
+	if err := env.auth.ResendOTP(ctx, model.ResendOTPInput{Email: testEmail}); err != nil {
+		t.Fatalf("ResendOTP: %v", err)
+	}
+	if _, err := env.auth.VerifyOTP(ctx, testEmail, "wrong"); !errors.Is(err, model.ErrInvalidOTP) {
+		t.Fatalf("VerifyOTP with wrong code: got %v, want ErrInvalidOTP", err)
+	}
+
+	code := otpPattern.FindString(env.mail.last(t, testEmail))
+	if _, err := env.auth.VerifyOTP(ctx, testEmail, code); !errors.Is(err, model.ErrInvalidOTP) {
+		t.Fatalf("VerifyOTP after the attempt limit: got %v, want ErrInvalidOTP", err)
+	}
+}
+
+func TestResendOTPCooldown(t *testing.T) {
+	env := newTestEnv(t)
+	env.register(t)
+
+	if err := env.auth.ResendOTP(context.Background(), model.ResendOTPInput{Email: testEmail}); !errors.Is(err, model.ErrOTPRateLimited) {
+		t.Fatalf("ResendOTP during cooldown: got %v, want ErrOTPRateLimited", err)
+	}
+}
+
+func TestUpdatePhoneIsRateLimited(t *testing.T) {
+	sms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer sms.Close()
+
+	ctx := context.Background()
+	env := newTestEnv(t, "-sms-provider=http", "-sms-http-url="+sms.URL)
+	userID := env.verifiedUserID(t)
+
+	if err := env.auth.UpdatePhone(ctx, userID, model.UpdatePhoneInput{Phone: "+6281200000001"}); err != nil {
+		t.Fatalf("UpdatePhone: %v", err)
+	}
+	if err := env.auth.UpdatePhone(ctx, userID, model.UpdatePhoneInput{Phone: "+6281200000002"}); !errors.Is(err, model.ErrOTPRateLimited) {
+		t.Fatalf("UpdatePhone during cooldown: got %v, want ErrOTPRateLimited", err)
+	}
+
+	env = newTestEnv(t, "-sms-provider=http", "-sms-http-url="+sms.URL, "-otp-resend-cooldown=0", "-phone-otp-daily-limit=2")
+	userID = env.verifiedUserID(t)
+	for i := 0; i < 2; i++ {
+		if err := env.auth.UpdatePhone(ctx, userID, model.UpdatePhoneInput{Phone: "+6281200000001"}); err != nil {
+			t.Fatalf("UpdatePhone %d: %v", i+1, err)
+		}
+	}
+	if err := env.auth.UpdatePhone(ctx, userID, model.UpdatePhoneInput{Phone: "+6281200000001"}); !errors.Is(err, model.ErrOTPRateLimited) {
+		t.Fatalf("UpdatePhone over the daily limit: got %v, want ErrOTPRateLimited", err)
+	}
+}
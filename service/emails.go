@@ -0,0 +1,39 @@
+package service
+
+import (
+	"auth-service/config"
+	"auth-service/mailer"
+	"auth-service/model"
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// sendResetPasswordEmail menyusun email reset password dalam bahasa user dan mengirimkannya.
+// Jika link kosong, email hanya berisi token.
+func sendResetPasswordEmail(ctx context.Context, m mailer.Mailer, templates *mailer.Renderer, user *model.User, token, link string, validity time.Duration) error {
+	msg, err := templates.ResetPassword(user.Email, user.Locale, token, link, validity)
+	if err != nil {
+		return fmt.Errorf("could not render reset password email: %w", err)
+	}
+	return m.Send(ctx, msg)
+}
+
+// resetPasswordLink membangun tautan reset password dari template milik clientID, atau template
+// default jika clientID kosong. Mengembalikan string kosong jika tidak ada template yang dikonfigurasi.
+func resetPasswordLink(cfg *config.Config, clientID, token string) (string, error) {
+	tmpl := cfg.PasswordResetURL
+	if clientID != "" {
+		clientURL, ok := cfg.PasswordResetClientURLs[clientID]
+		if !ok {
+			return "", model.ErrInvalidClient
+		}
+		tmpl = clientURL
+	}
+	if tmpl == "" {
+		return "", nil
+	}
+	return strings.ReplaceAll(tmpl, "{token}", url.QueryEscape(token)), nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"auth-service/config"
+	"auth-service/mailer"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// Status hasil pemeriksaan kesehatan.
+const (
+	HealthOK          = "ok"
+	HealthDegraded    = "degraded"
+	HealthUnavailable = "unavailable"
+	HealthError       = "error"
+)
+
+// DependencyStatus adalah hasil pemeriksaan satu dependency.
+type DependencyStatus struct {
+	Status    string  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// HealthReport adalah hasil pemeriksaan readiness. Status "unavailable" jika dependency
+// kritis gagal, "degraded" jika hanya dependency non-kritis (misalnya SMTP) yang gagal.
+type HealthReport struct {
+	Status string                      `json:"status"`
+	Checks map[string]DependencyStatus `json:"checks"`
+}
+
+var errShuttingDown = errors.New("server is shutting down")
+
+type healthCheck struct {
+	name     string
+	critical bool
+	check    func(ctx context.Context) error
+}
+
+// HealthService memeriksa konektivitas ke Postgres, Redis dan (opsional) server SMTP. db dan
+// redisClient bernilai nil pada mode penyimpanan memori sehingga tidak diperiksa.
+type HealthService struct {
+	checks       []healthCheck
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+func NewHealthService(db *gorm.DB, redisClient *redis.Client, mail mailer.Mailer, cfg *config.Config) *HealthService {
+	s := &HealthService{timeout: cfg.HealthCheckTimeout}
+	if db != nil {
+		s.checks = append(s.checks, healthCheck{name: "postgres", critical: true, check: func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		}})
+	}
+	if redisClient != nil {
+		s.checks = append(s.checks, healthCheck{name: "redis", critical: true, check: func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		}})
+	}
+
+	// SMTP tidak kritis: email diantrekan dan dikirim ulang oleh worker saat server kembali.
+	if pinger, ok := mail.(mailer.Pinger); ok && cfg.HealthCheckSMTP {
+		s.checks = append(s.checks, healthCheck{name: "smtp", check: pinger.Ping})
+	}
+	return s
+}
+
+// MarkShuttingDown membuat Ready selalu melaporkan "unavailable" agar load balancer berhenti
+// mengirim request baru selama graceful shutdown.
+func (s *HealthService) MarkShuttingDown() {
+	s.shuttingDown.Store(true)
+}
+
+// Ready menjalankan semua pemeriksaan secara paralel, masing-masing dengan batas waktu sendiri.
+func (s *HealthService) Ready(ctx context.Context) HealthReport {
+	if s.shuttingDown.Load() {
+		return HealthReport{Status: HealthUnavailable, Checks: map[string]DependencyStatus{
+			"server": {Status: HealthError, Critical: true, Error: errShuttingDown.Error()},
+		}}
+	}
+
+	results := make([]DependencyStatus, len(s.checks))
+	var wg sync.WaitGroup
+	for i, hc := range s.checks {
+		wg.Add(1)
+		go func(i int, hc healthCheck) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, s.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := hc.check(checkCtx)
+			result := DependencyStatus{
+				Status:    HealthOK,
+				Critical:  hc.critical,
+				LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				result.Status = HealthError
+				result.Error = err.Error()
+			}
+			results[i] = result
+		}(i, hc)
+	}
+	wg.Wait()
+
+	report := HealthReport{Status: HealthOK, Checks: make(map[string]DependencyStatus, len(s.checks))}
+	for i, hc := range s.checks {
+		report.Checks[hc.name] = results[i]
+		if results[i].Status == HealthOK {
+			continue
+		}
+		if hc.critical {
+			report.Status = HealthUnavailable
+		} else if report.Status == HealthOK {
+			report.Status = HealthDegraded
+		}
+	}
+	return report
+}
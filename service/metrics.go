@@ -0,0 +1,23 @@
+package service
+
+import (
+	"auth-service/metrics"
+	"auth-service/model"
+	"errors"
+)
+
+// outcome mengubah error dari sebuah operasi menjadi label metrik: "success", kode AppError
+// (misalnya "invalid_credentials"), atau "error" untuk kegagalan internal.
+func outcome(err error) string {
+	if err == nil {
+		return metrics.OutcomeSuccess
+	}
+	var appErr *model.AppError
+	if errors.As(err, &appErr) {
+		if appErr.Code != "" {
+			return appErr.Code
+		}
+		return "rejected"
+	}
+	return "error"
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"auth-service/config"
+	"auth-service/model"
+	"auth-service/repository"
+	"context"
+	"log/slog"
+	"time"
+)
+
+const (
+	// outboxLeaseMargin ditambahkan ke waktu terlama pengiriman satu event (timeout setiap
+	// endpoint webhook) agar lease tidak habis selama event masih diproses.
+	outboxLeaseMargin = time.Minute
+	outboxMaxBackoff  = time.Hour
+	// outboxPruneInterval adalah jarak antar penghapusan event yang sudah selesai.
+	outboxPruneInterval = time.Hour
+)
+
+// OutboxHandler memproses event internal. Error membuat event dicoba ulang dengan backoff.
+type OutboxHandler func(ctx context.Context, event *model.OutboxEvent) error
+
+// OutboxService menyimpan event domain ke tabel outbox dan mengirimkannya dari latar belakang.
+// Event yang terkait perubahan user ditulis lewat UserRepo dalam transaksi yang sama;
+// Publish dipakai untuk event yang tidak disertai perubahan data. Event yang memiliki handler
+// (lihat Handle) diproses oleh handler tersebut dan tidak dikirim ke webhook.
+type OutboxService struct {
+	outboxRepo     repository.OutboxStore
+	webhookService *WebhookService
+	handlers       map[string]OutboxHandler
+	cfg            *config.Config
+}
+
+func NewOutboxService(outboxRepo repository.OutboxStore, webhookService *WebhookService, cfg *config.Config) *OutboxService {
+	return &OutboxService{
+		outboxRepo:     outboxRepo,
+		webhookService: webhookService,
+		handlers:       make(map[string]OutboxHandler),
+		cfg:            cfg,
+	}
+}
+
+// Handle mendaftarkan handler untuk event internal. Harus dipanggil sebelum Run.
+func (s *OutboxService) Handle(eventType string, handler OutboxHandler) {
+	s.handlers[eventType] = handler
+}
+
+// Publish menyimpan event ke outbox. Kegagalan hanya dicatat ke log agar tidak menggagalkan request.
+func (s *OutboxService) Publish(ctx context.Context, eventType string, data map[string]interface{}) {
+	if err := s.outboxRepo.Add(ctx, model.NewOutboxEvent(eventType, data)); err != nil {
+		slog.ErrorContext(ctx, "failed to store outbox event", "event_type", eventType, "error", err)
+	}
+}
+
+// Run menjalankan dispatcher hingga ctx dibatalkan. Event yang sudah diproses atau gagal
+// dihapus setelah melewati OutboxRetention.
+func (s *OutboxService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.OutboxPollInterval)
+	defer ticker.Stop()
+	var lastPrune time.Time
+
+	for {
+		s.dispatchBatch(ctx)
+		if time.Since(lastPrune) >= outboxPruneInterval {
+			s.prune(ctx)
+			lastPrune = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *OutboxService) prune(ctx context.Context) {
+	deleted, err := s.outboxRepo.DeleteFinishedBefore(ctx, time.Now().UTC().Add(-s.cfg.OutboxRetention))
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to prune outbox events", "error", err)
+		return
+	}
+	if deleted > 0 {
+		slog.InfoContext(ctx, "pruned outbox events", "deleted", deleted)
+	}
+}
+
+// dispatchBatch memproses hingga OutboxBatchSize event. Event diklaim satu per satu agar lease
+// hanya perlu mencakup satu event; event yang belum diproses tetap bisa diambil instance lain.
+func (s *OutboxService) dispatchBatch(ctx context.Context) {
+	lease := s.lease()
+	for i := 0; i < s.cfg.OutboxBatchSize && ctx.Err() == nil; i++ {
+		events, err := s.outboxRepo.Claim(ctx, 1, lease)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to claim outbox events", "error", err)
+			return
+		}
+		if len(events) == 0 {
+			return
+		}
+		s.dispatch(ctx, &events[0])
+	}
+}
+
+// lease adalah batas waktu terlama pemrosesan satu event: satu percobaan ke setiap endpoint
+// webhook ditambah outboxLeaseMargin.
+func (s *OutboxService) lease() time.Duration {
+	return time.Duration(len(s.cfg.WebhookEndpoints))*s.cfg.WebhookTimeout + outboxLeaseMargin
+}
+
+func (s *OutboxService) process(ctx context.Context, event *model.OutboxEvent) error {
+	if handler, ok := s.handlers[event.Type]; ok {
+		return handler(ctx, event)
+	}
+	return s.webhookService.Deliver(ctx, event)
+}
+
+func (s *OutboxService) dispatch(ctx context.Context, event *model.OutboxEvent) {
+	err := s.process(ctx, event)
+	if err == nil {
+		if err := s.outboxRepo.MarkProcessed(ctx, event.ID); err != nil {
+			slog.ErrorContext(ctx, "failed to mark outbox event as processed", "event_id", event.ID, "error", err)
+		}
+		return
+	}
+
+	attempts := event.Attempts + 1
+	if attempts >= s.cfg.OutboxMaxAttempts {
+		slog.ErrorContext(ctx, "giving up on outbox event", "event_id", event.ID, "event_type", event.Type, "attempts", attempts, "error", err)
+		if err := s.outboxRepo.MarkFailed(ctx, event.ID, attempts, err.Error()); err != nil {
+			slog.ErrorContext(ctx, "failed to mark outbox event as failed", "event_id", event.ID, "error", err)
+		}
+		return
+	}
+
+	backoff := outboxMaxBackoff
+	if attempts < 16 {
+		backoff = time.Second << attempts
+	}
+	if backoff > outboxMaxBackoff {
+		backoff = outboxMaxBackoff
+	}
+	if err := s.outboxRepo.MarkRetry(ctx, event.ID, attempts, err.Error(), time.Now().UTC().Add(backoff)); err != nil {
+		slog.ErrorContext(ctx, "failed to reschedule outbox event", "event_id", event.ID, "error", err)
+	}
+}
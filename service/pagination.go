@@ -0,0 +1,20 @@
+package service
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// normalizePage menerapkan nilai default dan batas atas untuk parameter paginasi.
+func normalizePage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	return page, pageSize
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"auth-service/config"
+	"auth-service/model"
+	"auth-service/repository"
+	"auth-service/utils"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// WebhookService mengirim event siklus hidup akun ke endpoint HTTP yang dikonfigurasi.
+// Setiap request ditandatangani dengan HMAC; percobaan ulang diatur oleh OutboxService.
+type WebhookService struct {
+	webhookRepo repository.WebhookStore
+	client      *http.Client
+	cfg         *config.Config
+}
+
+func NewWebhookService(webhookRepo repository.WebhookStore, cfg *config.Config) *WebhookService {
+	return &WebhookService{
+		webhookRepo: webhookRepo,
+		client:      &http.Client{Timeout: cfg.WebhookTimeout},
+		cfg:         cfg,
+	}
+}
+
+// WebhookDeliveryPage adalah satu halaman log pengiriman webhook.
+type WebhookDeliveryPage struct {
+	Deliveries []model.WebhookDelivery `json:"deliveries"`
+	Page       int                     `json:"page"`
+	PageSize   int                     `json:"page_size"`
+	Total      int64                   `json:"total"`
+}
+
+// Deliver mengirim event ke semua endpoint yang belum menerimanya dan mengembalikan error
+// jika masih ada endpoint yang gagal. Setiap percobaan dicatat di log pengiriman.
+func (s *WebhookService) Deliver(ctx context.Context, event *model.OutboxEvent) error {
+	if len(s.cfg.WebhookEndpoints) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(model.WebhookEvent{
+		ID:        event.ID,
+		Type:      event.Type,
+		CreatedAt: event.CreatedAt,
+		Data:      event.Payload,
+	})
+	if err != nil {
+		return fmt.Errorf("could not encode webhook event: %w", err)
+	}
+
+	var failed []string
+	for _, url := range s.cfg.WebhookEndpoints {
+		delivered, err := s.webhookRepo.HasSucceeded(ctx, event.ID, url)
+		if err != nil {
+			return fmt.Errorf("could not check webhook delivery log: %w", err)
+		}
+		if delivered {
+			continue
+		}
+
+		delivery := s.send(ctx, event, url, body)
+		if err := s.webhookRepo.CreateDelivery(ctx, delivery); err != nil {
+			slog.WarnContext(ctx, "failed to record webhook delivery", "event_id", event.ID, "url", url, "error", err)
+		}
+		if !delivery.Succeeded {
+			failed = append(failed, fmt.Sprintf("%s: %s", url, delivery.Error))
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("webhook delivery failed: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+func (s *WebhookService) ListDeliveries(ctx context.Context, filter model.WebhookDeliveryFilter) (*WebhookDeliveryPage, error) {
+	page, pageSize := normalizePage(filter.Page, filter.PageSize)
+
+	deliveries, total, err := s.webhookRepo.ListDeliveries(ctx, filter, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("could not list webhook deliveries: %w", err)
+	}
+
+	return &WebhookDeliveryPage{
+		Deliveries: deliveries,
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+	}, nil
+}
+
+func (s *WebhookService) send(ctx context.Context, event *model.OutboxEvent, url string, body []byte) *model.WebhookDelivery {
+	delivery := &model.WebhookDelivery{
+		EventID:   event.ID,
+		EventType: event.Type,
+		URL:       url,
+		Attempt:   event.Attempts + 1,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Id", event.ID)
+	req.Header.Set("X-Webhook-Event", event.Type)
+	req.Header.Set("X-Webhook-Signature", utils.SignWebhookPayload(s.cfg.WebhookSecret, time.Now().Unix(), body))
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	delivery.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	delivery.StatusCode = resp.StatusCode
+	delivery.Succeeded = resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !delivery.Succeeded {
+		delivery.Error = resp.Status
+	}
+	return delivery
+}
+
+// userEventData adalah isi "data" standar untuk event yang berkaitan dengan user.
+func userEventData(user *model.User) map[string]interface{} {
+	return map[string]interface{}{
+		"user_id": user.ID.String(),
+		"email":   user.Email,
+	}
+}
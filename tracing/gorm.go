@@ -0,0 +1,82 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const gormSpanKey = "tracing:span"
+
+// InstrumentGORM mendaftarkan callback GORM yang membuat span untuk setiap query.
+// Context diambil dari db.WithContext sehingga span menjadi anak dari span request.
+func InstrumentGORM(db *gorm.DB) error {
+	type processor interface {
+		Register(name string, fn func(*gorm.DB)) error
+	}
+	register := func(operation string, before, after processor) error {
+		if err := before.Register("tracing:before_"+operation, startSpan(operation)); err != nil {
+			return err
+		}
+		return after.Register("tracing:after_"+operation, endSpan)
+	}
+
+	cb := db.Callback()
+	if err := register("create", cb.Create().Before("gorm:create"), cb.Create().After("gorm:create")); err != nil {
+		return err
+	}
+	if err := register("query", cb.Query().Before("gorm:query"), cb.Query().After("gorm:query")); err != nil {
+		return err
+	}
+	if err := register("update", cb.Update().Before("gorm:update"), cb.Update().After("gorm:update")); err != nil {
+		return err
+	}
+	if err := register("delete", cb.Delete().Before("gorm:delete"), cb.Delete().After("gorm:delete")); err != nil {
+		return err
+	}
+	if err := register("row", cb.Row().Before("gorm:row"), cb.Row().After("gorm:row")); err != nil {
+		return err
+	}
+	return register("raw", cb.Raw().Before("gorm:raw"), cb.Raw().After("gorm:raw"))
+}
+
+func startSpan(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil {
+			return
+		}
+		name := "gorm." + operation
+		if db.Statement.Table != "" {
+			name += " " + db.Statement.Table
+		}
+		_, span := Tracer().Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBOperationName(operation)))
+		db.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func endSpan(db *gorm.DB) {
+	value, ok := db.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	// SQL memakai placeholder sehingga nilai parameter (email, hash password) tidak ikut tercatat.
+	span.SetAttributes(
+		semconv.DBCollectionName(db.Statement.Table),
+		semconv.DBQueryText(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.RowsAffected),
+	)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		recordError(span, db.Error)
+	}
+}
@@ -0,0 +1,64 @@
+// Package tracing menyiapkan OpenTelemetry tracing: provider dengan exporter OTLP/HTTP,
+// propagasi W3C trace context, serta instrumentasi untuk GORM dan Redis.
+package tracing
+
+import (
+	"auth-service/config"
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "auth-service"
+
+// Tracer mengembalikan tracer aplikasi. Aman dipanggil sebelum Setup karena provider global
+// otel meneruskan span ke provider yang dipasang belakangan.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Setup memasang propagator W3C (traceparent dan baggage) dan, jika tracing diaktifkan,
+// tracer provider yang mengekspor span lewat OTLP/HTTP. Fungsi yang dikembalikan harus
+// dipanggil saat shutdown agar span yang tersisa terkirim.
+func Setup(ctx context.Context, cfg *config.Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if !cfg.TracingEnabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var opts []otlptracehttp.Option
+	if cfg.TracingEndpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpointURL(cfg.TracingEndpoint))
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not create OTLP exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.TracingServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("could not build tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.TracingSampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
@@ -7,6 +7,13 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// TokenClaims adalah klaim yang dibaca dari access token.
+// ActorID terisi jika token adalah hasil impersonasi (klaim "act" RFC 8693).
+type TokenClaims struct {
+	UserID  string
+	ActorID string
+}
+
 // GenerateJWT membuat token JWT baru. Menerima userID sebagai string.
 func GenerateJWT(userID string, secret string, duration time.Duration) (string, error) {
 	claims := jwt.MapClaims{
@@ -19,27 +26,64 @@ func GenerateJWT(userID string, secret string, duration time.Duration) (string,
 	return token.SignedString([]byte(secret))
 }
 
-func ValidateJWT(tokenStr string, secret string) (string, error) {
+// GenerateImpersonationJWT membuat token atas nama userID dengan klaim "act" berisi actorID.
+func GenerateImpersonationJWT(userID, actorID string, secret string, duration time.Duration) (string, error) {
+	claims := jwt.MapClaims{
+		"sub": userID,
+		"act": map[string]string{"sub": actorID},
+		"exp": time.Now().Add(duration).Unix(),
+		"iat": time.Now().Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(secret))
+}
+
+func ValidateJWT(tokenStr string, secrets ...string) (string, error) {
+	claims, err := ParseJWT(tokenStr, secrets...)
+	if err != nil {
+		return "", err
+	}
+	return claims.UserID, nil
+}
+
+// ParseJWT memvalidasi token dan mengembalikan klaim yang relevan. Token diterima jika
+// ditandatangani dengan salah satu secret, sehingga secret lama tetap berlaku selama rotasi.
+func ParseJWT(tokenStr string, secrets ...string) (*TokenClaims, error) {
+	keys := jwt.VerificationKeySet{}
+	for _, secret := range secrets {
+		keys.Keys = append(keys.Keys, []byte(secret))
+	}
+
 	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("unexpected signing method")
 		}
-		return []byte(secret), nil
+		return keys, nil
 	})
 
 	if err != nil || !token.Valid {
-		return "", errors.New("invalid token")
+		return nil, errors.New("invalid token")
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return "", errors.New("invalid token claims")
+		return nil, errors.New("invalid token claims")
 	}
 
 	sub, ok := claims["sub"].(string)
 	if !ok {
-		return "", errors.New("invalid sub")
+		return nil, errors.New("invalid sub")
+	}
+
+	result := &TokenClaims{UserID: sub}
+	if act, ok := claims["act"].(map[string]interface{}); ok {
+		actorID, ok := act["sub"].(string)
+		if !ok {
+			return nil, errors.New("invalid act claim")
+		}
+		result.ActorID = actorID
 	}
 
-	return sub, nil
+	return result, nil
 }
@@ -18,3 +18,8 @@ func WriteJSON(w http.ResponseWriter, status int, data interface{}) {
 func WriteError(w http.ResponseWriter, status int, message string) {
 	WriteJSON(w, status, map[string]string{"error": message})
 }
+
+// WriteErrorWithCode menulis respons error JSON beserta kode error yang bisa dibaca mesin.
+func WriteErrorWithCode(w http.ResponseWriter, status int, code, message string) {
+	WriteJSON(w, status, map[string]string{"error": message, "code": code})
+}
@@ -0,0 +1,52 @@
+package utils
+
+import "strings"
+
+// DeviceName meringkas user agent menjadi keluarga browser dan sistem operasi, misalnya
+// "Chrome on Windows". Nomor versi diabaikan agar pembaruan browser tidak dianggap
+// perangkat baru.
+func DeviceName(userAgent string) string {
+	if userAgent == "" {
+		return ""
+	}
+	return browserFamily(userAgent) + " on " + osFamily(userAgent)
+}
+
+func browserFamily(ua string) string {
+	switch {
+	case strings.Contains(ua, "Edg/") || strings.Contains(ua, "Edge/"):
+		return "Edge"
+	case strings.Contains(ua, "OPR/") || strings.Contains(ua, "Opera"):
+		return "Opera"
+	case strings.Contains(ua, "SamsungBrowser/"):
+		return "Samsung Internet"
+	case strings.Contains(ua, "Firefox/") || strings.Contains(ua, "FxiOS/"):
+		return "Firefox"
+	case strings.Contains(ua, "Chrome/") || strings.Contains(ua, "CriOS/"):
+		return "Chrome"
+	case strings.Contains(ua, "Safari/"):
+		return "Safari"
+	}
+	// Klien non-browser (curl, aplikasi mobile) biasanya diawali "nama/versi".
+	product, _, _ := strings.Cut(ua, "/")
+	product, _, _ = strings.Cut(product, " ")
+	return product
+}
+
+func osFamily(ua string) string {
+	switch {
+	case strings.Contains(ua, "Windows"):
+		return "Windows"
+	case strings.Contains(ua, "Android"):
+		return "Android"
+	case strings.Contains(ua, "iPhone") || strings.Contains(ua, "iPad") || strings.Contains(ua, "iOS"):
+		return "iOS"
+	case strings.Contains(ua, "Mac OS X") || strings.Contains(ua, "Macintosh"):
+		return "macOS"
+	case strings.Contains(ua, "CrOS"):
+		return "ChromeOS"
+	case strings.Contains(ua, "Linux"):
+		return "Linux"
+	}
+	return "unknown OS"
+}
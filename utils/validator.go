@@ -3,7 +3,7 @@ package utils
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
 
@@ -15,10 +15,11 @@ func NewValidator() *validator.Validate {
 }
 
 func DecodeAndValidate(r *http.Request, v interface{}, validate *validator.Validate) error {
-	log.Printf("INFO: Decoding and validating request for %s %s", r.Method, r.URL.Path)
+	ctx := r.Context()
+	slog.DebugContext(ctx, "decoding and validating request", "method", r.Method, "path", r.URL.Path)
 
 	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
-		log.Printf("ERROR: Failed to decode request body: %v", err)
+		slog.WarnContext(ctx, "failed to decode request body", "method", r.Method, "path", r.URL.Path, "error", err)
 		return fmt.Errorf("invalid request body: %w", err)
 	}
 	defer r.Body.Close()
@@ -29,10 +30,10 @@ func DecodeAndValidate(r *http.Request, v interface{}, validate *validator.Valid
 			errorMsg.WriteString(fmt.Sprintf("field '%s' failed on the '%s' tag; ", err.Field(), err.Tag()))
 		}
 		formattedError := fmt.Errorf("validation error: %s", strings.TrimSuffix(errorMsg.String(), "; "))
-		log.Printf("WARN: Validation failed for %s %s: %v", r.Method, r.URL.Path, formattedError)
+		slog.WarnContext(ctx, "request validation failed", "method", r.Method, "path", r.URL.Path, "error", formattedError)
 		return formattedError
 	}
 
-	log.Printf("INFO: Request validation successful for %s %s", r.Method, r.URL.Path)
+	slog.DebugContext(ctx, "request validation successful", "method", r.Method, "path", r.URL.Path)
 	return nil
 }
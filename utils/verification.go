@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidVerificationToken dikembalikan jika token verifikasi email rusak, palsu atau kedaluwarsa.
+var ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+
+// SignEmailVerificationToken membuat token "<payload>.<signature>" untuk tautan verifikasi email.
+// Payload berisi user ID dan waktu kedaluwarsa; email ikut ditandatangani sehingga token
+// otomatis tidak berlaku jika email akun berubah.
+func SignEmailVerificationToken(secret, userID, email string, expiresAt time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(userID + "." + strconv.FormatInt(expiresAt.Unix(), 10)))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(verificationMAC(secret, payload, email))
+}
+
+// ParseEmailVerificationToken mengembalikan user ID dari token tanpa memeriksa tanda tangan.
+// Pemanggil harus memanggil VerifyEmailVerificationToken dengan email user tersebut.
+func ParseEmailVerificationToken(token string) (string, error) {
+	payload, _, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", ErrInvalidVerificationToken
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", ErrInvalidVerificationToken
+	}
+	userID, _, ok := strings.Cut(string(raw), ".")
+	if !ok {
+		return "", ErrInvalidVerificationToken
+	}
+	return userID, nil
+}
+
+// VerifyEmailVerificationToken memeriksa tanda tangan dan masa berlaku token untuk email tertentu.
+// Tanda tangan diterima jika cocok dengan salah satu secret.
+func VerifyEmailVerificationToken(secrets []string, token, email string, now time.Time) error {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return ErrInvalidVerificationToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidVerificationToken
+	}
+	signed := false
+	for _, secret := range secrets {
+		if hmac.Equal(sig, verificationMAC(secret, payload, email)) {
+			signed = true
+			break
+		}
+	}
+	if !signed {
+		return ErrInvalidVerificationToken
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return ErrInvalidVerificationToken
+	}
+	_, exp, ok := strings.Cut(string(raw), ".")
+	if !ok {
+		return ErrInvalidVerificationToken
+	}
+	expiresAt, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || now.Unix() > expiresAt {
+		return ErrInvalidVerificationToken
+	}
+	return nil
+}
+
+func verificationMAC(secret, payload, email string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("email-verification."))
+	mac.Write([]byte(payload))
+	mac.Write([]byte("."))
+	mac.Write([]byte(strings.ToLower(email)))
+	return mac.Sum(nil)
+}
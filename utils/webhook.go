@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+)
+
+// SignWebhookPayload menghasilkan nilai header X-Webhook-Signature dengan format "t=<unix>,v1=<hmac>".
+// HMAC-SHA256 dihitung atas "<unix>.<body>" sehingga penerima dapat menolak payload lama (replay).
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	ts := strconv.FormatInt(timestamp, 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return fmt.Sprintf("t=%s,v1=%s", ts, hex.EncodeToString(mac.Sum(nil)))
+}